@@ -0,0 +1,156 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/manifest"
+)
+
+const manifestHelp = `A manifest is a YAML file describing which encrypted settings go into which
+configuration files, where their plain text comes from, and which key is used:
+
+  version: 1
+  keys:
+    connect:
+      product: connect          # or keyfile: PATH, or key: LABEL
+      product_version: 2024.05.0
+      mode: fips                # optional
+  files:
+    - path: /etc/rstudio-connect/rstudio-connect.gcfg
+      format: gcfg              # optional: "gcfg" or "workbench"
+      key: connect
+      settings:
+        - name: Postgres.Password
+          env: CONNECT_DB_PASSWORD
+        - name: OAuth2.ClientSecret
+          file: /run/secrets/oauth-client-secret
+
+Settings in gcfg files are named Section.Name or Section.subsection.Name.
+Relative paths are relative to the manifest's directory. Existing values are
+decrypted and kept if they already match, so unchanged settings never change.
+`
+
+var planCmd = &cobra.Command{
+	Use:   "plan MANIFEST",
+	Short: "Show the changes needed to make configuration files match a manifest",
+	Long: `Compare configuration files with a manifest, and show which settings would be
+added or changed by "rskey apply". Plain text is never shown.
+
+` + manifestHelp + `
+Examples:
+  rskey plan secrets.yaml
+  rskey plan --format=json secrets.yaml
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlan,
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply MANIFEST",
+	Short: "Update configuration files to match a manifest",
+	Long: `Update configuration files so that they contain the encrypted settings in a
+manifest. Every file is checked before any is written, and nothing is written if
+a file changes in the meantime. Existing files are backed up to
+PATH.TIMESTAMP.bak and replaced atomically, keeping their permissions; new
+files are only readable by their owner. If any file cannot be written, those
+already written are restored, so a manifest is applied in full or not at all.
+
+` + manifestHelp + `
+Examples:
+  rskey plan secrets.yaml && rskey apply secrets.yaml
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runApply,
+}
+
+// makePlan loads a manifest and plans the changes it requires.
+func makePlan(cmd *cobra.Command, path string) (*manifest.Plan, error) {
+	m, err := manifest.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	planner := &manifest.Planner{
+		Cipher: func(name string, spec manifest.KeySpec) (crypt.Cipher, error) {
+			mode := spec.Mode
+			if mode == "" {
+				mode = cmd.Flag("mode").Value.String()
+			}
+			src := keySource{
+				keyfile:        spec.Keyfile,
+				label:          spec.Key,
+				mode:           mode,
+				explicitMode:   spec.Mode != "",
+				product:        spec.Product,
+				productVersion: spec.ProductVersion,
+				productKeyfile: true,
+				keystore:       cmd.Flag("keystore").Value.String(),
+				keyfileName:    "keyfile",
+				labelName:      "key",
+			}
+			return src.load(useEncrypt)
+		},
+	}
+	return planner.Plan(m)
+}
+
+// printPlan writes the plan in the format given by the "format" flag.
+func printPlan(cmd *cobra.Command, plan *manifest.Plan) error {
+	switch format := cmd.Flag("format").Value.String(); format {
+	case "json":
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	case "text":
+		return plan.WriteText(cmd.OutOrStdout())
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	plan, err := makePlan(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	return printPlan(cmd, plan)
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	plan, err := makePlan(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	if err := printPlan(cmd, plan); err != nil {
+		return err
+	}
+	if plan.Empty() {
+		return nil
+	}
+	backups, err := plan.Apply(time.Now())
+	// Backups are only returned on failure if restoring a file also failed,
+	// so report them for manual recovery.
+	for _, b := range backups {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Backed up to %s\n", b)
+	}
+	if err != nil {
+		return err
+	}
+	create, update, _ := plan.Counts()
+	fmt.Fprintf(cmd.ErrOrStderr(), "Apply complete: %d added, %d changed.\n", create, update)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(applyCmd)
+	addFormatFlag(planCmd, "text", "json")
+	addFormatFlag(applyCmd, "text", "json")
+}
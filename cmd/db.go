@@ -0,0 +1,113 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	// Register the PostgreSQL and SQLite database drivers.
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"github.com/rstudio/rskey/dbrekey"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manage secrets stored in databases",
+}
+
+var dbRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Re-encrypt a database column under a new key",
+	Long: `Re-encrypt every value in a database column that was encrypted with
+one key so that it uses another. Rows are processed in batches, each inside a
+transaction, and values already encrypted with the new key, in the format it
+would use now, are skipped so that an interrupted run can safely be repeated.
+
+Supported drivers are "postgres" and "sqlite". The data source name can also
+be passed in the RSKEY_DSN environment variable, to keep database passwords
+out of process listings.
+
+Examples:
+  rskey db rekey --driver=sqlite --dsn=app.db --table=credentials \
+    --column=password -f old.key --new-keyfile=new.key
+  RSKEY_DSN="postgres://app@db/app" rskey db rekey --driver=postgres \
+    --table=credentials --column=password -f old.key \
+    --new-keyfile=new.key --new-mode=fips
+`,
+	Args: cobra.NoArgs,
+	RunE: runDBRekey,
+}
+
+func runDBRekey(cmd *cobra.Command, args []string) error {
+	old, err := loadCipher(cmd, useDecrypt)
+	if err != nil {
+		return err
+	}
+	newCipher, err := loadCipherFrom(cmd, useEncrypt, "new-keyfile", "new-key", "new-mode")
+	if err != nil {
+		return err
+	}
+	driver, _ := cmd.Flags().GetString("driver")
+	placeholders := dbrekey.Question
+	switch driver {
+	case "postgres":
+		placeholders = dbrekey.Dollar
+	case "sqlite":
+	default:
+		return fmt.Errorf("unsupported database driver %q", driver)
+	}
+	dsn, _ := cmd.Flags().GetString("dsn")
+	if dsn == "" {
+		dsn = os.Getenv("RSKEY_DSN")
+	}
+	if dsn == "" {
+		return fmt.Errorf("dsn is missing but must be provided")
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	opts := dbrekey.Options{
+		Old:          old,
+		New:          newCipher,
+		Placeholders: placeholders,
+	}
+	opts.Table, _ = cmd.Flags().GetString("table")
+	opts.Column, _ = cmd.Flags().GetString("column")
+	opts.IDColumn, _ = cmd.Flags().GetString("id-column")
+	opts.BatchSize, _ = cmd.Flags().GetInt("batch-size")
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	result, err := dbrekey.Rekey(cmd.Context(), db, opts)
+	verb := "Re-encrypted"
+	if opts.DryRun {
+		verb = "Would re-encrypt"
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%s %d values in %d batches (%d skipped)\n",
+		verb, result.Rekeyed, result.Batches, result.Skipped)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbRekeyCmd)
+	dbRekeyCmd.Flags().String("new-keyfile", "", "Encrypt values with this key file")
+	dbRekeyCmd.Flags().String("new-key", "", "Encrypt values with the key with this label in the keystore")
+	dbRekeyCmd.Flags().String("new-mode", "default", modeUsage())
+	dbRekeyCmd.Flags().String("driver", "postgres", `Either "postgres" or "sqlite"`)
+	dbRekeyCmd.Flags().String("dsn", "", "The database data source name")
+	dbRekeyCmd.Flags().String("table", "", "The table to update")
+	dbRekeyCmd.Flags().String("column", "", "The column containing encrypted values")
+	dbRekeyCmd.Flags().String("id-column", "id", "A unique column used to page through the table")
+	dbRekeyCmd.Flags().Int("batch-size", dbrekey.DefaultBatchSize, "Rows to update in each transaction")
+	dbRekeyCmd.Flags().Bool("dry-run", false, "Roll back every transaction instead of committing")
+	_ = dbRekeyCmd.MarkFlagRequired("table")
+	_ = dbRekeyCmd.MarkFlagRequired("column")
+}
@@ -10,9 +10,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
-
-	"github.com/rstudio/rskey/crypt"
-	"github.com/rstudio/rskey/workbench"
 )
 
 // decryptCmd represents the decrypt command
@@ -25,34 +22,23 @@ standard input.
 Examples:
   echo "G8QSoVOR936MjjMdjFqvXYqM+m1zwH0H/aX0fO5RGg0logwPOhME0Wz0sp9g4fMtYdw=" | \
     rskey decrypt -f /var/lib/rstudio-pm/rstudio-pm.key
+
+With --format=dotenv or --format=csv, standard input is a .env or CSV file, and
+only the variables given by --vars or the columns given by --columns are
+decrypted:
+
+  rskey decrypt -f secret.key --format=csv --columns=password < users.csv
 `,
 	RunE: runDecrypt,
 }
 
 func runDecrypt(cmd *cobra.Command, args []string) error {
-	keyfile := cmd.Flag("keyfile").Value.String()
-	if keyfile == "" {
-		return fmt.Errorf("keyfile is missing but must be provided")
-	}
-	f, err := os.Open(keyfile)
+	key, err := loadCipher(cmd, useDecrypt)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	var decrypt func(string) (string, error)
-	switch cmd.Flag("mode").Value.String() {
-	case "workbench":
-		key, err := workbench.NewKeyFromReader(f)
-		if err != nil {
-			return err
-		}
-		decrypt = key.Decrypt
-	default:
-		key, err := crypt.NewKeyFromReader(f)
-		if err != nil {
-			return err
-		}
-		decrypt = key.Decrypt
+	if format := cmd.Flag("format").Value.String(); isStreamFormat(format) {
+		return transformStream(cmd, key, format, false)
 	}
 	// Check if there's actually data in standard input.
 	info, err := os.Stdin.Stat()
@@ -63,7 +49,7 @@ func runDecrypt(cmd *cobra.Command, args []string) error {
 	if info.Mode()&os.ModeNamedPipe != 0 {
 		scanner := bufio.NewScanner(os.Stdin)
 		for scanner.Scan() {
-			cipher, err := decrypt(scanner.Text())
+			cipher, err := key.Decrypt(scanner.Text())
 			if err != nil {
 				return err
 			}
@@ -90,7 +76,7 @@ func runDecrypt(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	cipher, err := decrypt(data)
+	cipher, err := key.Decrypt(data)
 	if err != nil {
 		return err
 	}
@@ -100,7 +86,5 @@ func runDecrypt(cmd *cobra.Command, args []string) error {
 
 func init() {
 	rootCmd.AddCommand(decryptCmd)
-	decryptCmd.Flags().StringP("keyfile", "f", "", "Use the given key file")
-	decryptCmd.Flags().StringP("mode", "", "default",
-		`One of "default" or "workbench"`)
+	addStreamFlags(decryptCmd)
 }
@@ -0,0 +1,193 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/document"
+)
+
+var docCmd = &cobra.Command{
+	Use:   "doc",
+	Short: "Encrypt or decrypt values in JSON, YAML, and TOML documents",
+	Long: `Encrypt or decrypt selected string values in JSON, YAML, and TOML
+documents, such as Helm values files or R config.yml files, leaving everything
+else, including comments and ordering, unchanged.
+
+Values are selected with --path, a JSONPath-style selector such as
+.connect.db.password, .users[*].token, or .servers.*["api key"], or with
+--key-regex, which matches the key of a value anywhere in the document. It is
+an error for a --path to match nothing, or to select a value that cannot be
+rewritten, such as a YAML block scalar or a TOML multi-line string.
+
+Encrypted values are written as ENC[...], so that encrypting a document again
+leaves them alone. With --no-marker, bare cipher text is written instead, and
+values that the key can already decrypt are skipped.
+
+The document is written to standard output unless --in-place is given. Pass
+"-" to read it from standard input, along with --type.
+
+Examples:
+  rskey doc encrypt -f secret.key --path .connect.db.password values.yaml
+  rskey doc encrypt -f secret.key --key-regex '(?i)password|secret' -i config.yml
+  rskey doc decrypt -f secret.key --key-regex . --type=toml - < service.toml
+`,
+}
+
+var docEncryptCmd = &cobra.Command{
+	Use:   "encrypt FILE",
+	Short: "Encrypt selected values in a document",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoc(cmd, args[0], true)
+	},
+}
+
+var docDecryptCmd = &cobra.Command{
+	Use:   "decrypt FILE",
+	Short: "Decrypt selected values in a document",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoc(cmd, args[0], false)
+	},
+}
+
+// docMatcher builds a Matcher from the "path" and "key-regex" flags.
+func docMatcher(cmd *cobra.Command) (document.Matcher, error) {
+	paths, _ := cmd.Flags().GetStringArray("path")
+	patterns, _ := cmd.Flags().GetStringArray("key-regex")
+	if len(paths) == 0 && len(patterns) == 0 {
+		return nil, errors.New("at least one of --path or --key-regex must be provided")
+	}
+	m := document.AnyOf{}
+	for _, p := range paths {
+		sel, err := document.ParseSelector(p)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, sel)
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --key-regex: %w", err)
+		}
+		m = append(m, document.KeyPattern{Regexp: re})
+	}
+	return m, nil
+}
+
+// checkPaths returns an error if a "path" flag matches no string value in doc,
+// which is most likely a mistake.
+func checkPaths(cmd *cobra.Command, doc *document.Document) error {
+	paths, _ := cmd.Flags().GetStringArray("path")
+	values := doc.Values()
+	for _, p := range paths {
+		sel, err := document.ParseSelector(p)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(values, func(v document.Value) bool { return sel.Match(v.Path) }) {
+			return fmt.Errorf("--path %s matches no string values", p)
+		}
+	}
+	return nil
+}
+
+func runDoc(cmd *cobra.Command, path string, encrypt bool) error {
+	m, err := docMatcher(cmd)
+	if err != nil {
+		return err
+	}
+	var format document.Format
+	if t := cmd.Flag("type").Value.String(); t != "" {
+		if format, err = document.ParseFormat(t); err != nil {
+			return err
+		}
+	} else if format, err = document.DetectFormat(path); err != nil {
+		return fmt.Errorf("%w; use --type", err)
+	}
+	inPlace, _ := cmd.Flags().GetBool("in-place")
+	var data []byte
+	if path == "-" {
+		if inPlace {
+			return errors.New("--in-place cannot be used with standard input")
+		}
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	doc, err := document.Parse(format, data)
+	if err != nil {
+		return err
+	}
+	if err := checkPaths(cmd, doc); err != nil {
+		return err
+	}
+	use := useDecrypt
+	if encrypt {
+		use = useEncrypt
+	}
+	c, err := loadCipher(cmd, use)
+	if err != nil {
+		return err
+	}
+	noMarker, _ := cmd.Flags().GetBool("no-marker")
+	var n int
+	if encrypt {
+		n, err = doc.Encrypt(c, m, !noMarker)
+	} else {
+		n, err = doc.Decrypt(c, m, !noMarker)
+	}
+	if err != nil {
+		return err
+	}
+	if !inPlace {
+		_, err = cmd.OutOrStdout().Write(doc.Bytes())
+		return err
+	}
+	if n > 0 {
+		if err := conffile.WriteFile(path, doc.Bytes(), conffile.ExistingPerm(path, 0600)); err != nil {
+			return err
+		}
+	}
+	verb := "Decrypted"
+	if encrypt {
+		verb = "Encrypted"
+	}
+	noun := "values"
+	if n == 1 {
+		noun = "value"
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s %d %s in %s\n", verb, n, noun, path)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(docCmd)
+	docCmd.AddCommand(docEncryptCmd)
+	docCmd.AddCommand(docDecryptCmd)
+	docCmd.PersistentFlags().StringArray("path", nil,
+		"Select values with this JSONPath-style selector (repeatable)")
+	docCmd.PersistentFlags().StringArray("key-regex", nil,
+		"Select values whose key matches this regular expression (repeatable)")
+	docCmd.PersistentFlags().String("type", "",
+		`The document type: "json", "yaml", or "toml" (detected from the file name by default)`)
+	docCmd.PersistentFlags().BoolP("in-place", "i", false,
+		"Rewrite the file instead of writing to standard output")
+	docCmd.PersistentFlags().Bool("no-marker", false,
+		"Write bare cipher text instead of ENC[...]")
+}
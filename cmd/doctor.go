@@ -0,0 +1,100 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the cryptographic environment",
+	Long: `Report the cryptographic module and FIPS mode in use, and check that
+each encryption mode works in this environment. If a key file is given, it is
+also checked.
+
+Examples:
+  rskey doctor
+  rskey doctor --fips-policy=enforce
+  rskey doctor -f /var/lib/rstudio-pm/rstudio-pm.key
+`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	w := cmd.OutOrStdout()
+	_, err := fmt.Fprintf(w, "rskey %s\n", Version)
+	if err != nil {
+		return err
+	}
+	if err := writeCryptoInfo(w); err != nil {
+		return err
+	}
+	// Check each mode using a throwaway key, which is long enough to be
+	// accepted by all of them.
+	key, err := crypt.NewKey()
+	if err != nil {
+		return err
+	}
+	failed := false
+	fmt.Fprintln(w, "\nModes:")
+	for _, m := range crypt.Modes() {
+		c, err := crypt.LoadCipher(m.Name, strings.NewReader(key.HexString()))
+		if err == nil {
+			err = checkRoundTrip(c)
+		}
+		failed = failed || err != nil
+		fmt.Fprintf(w, "  %-12s %s\n", m.Name, doctorResult(err))
+	}
+	if cmd.Flag("keyfile").Value.String() != "" || cmd.Flag("key").Value.String() != "" {
+		c, err := loadCipher(cmd, useAny)
+		if err == nil {
+			err = checkRoundTrip(c)
+		}
+		failed = failed || err != nil
+		fmt.Fprintf(w, "\nKey file:\n  %-12s %s\n", cmd.Flag("mode").Value,
+			doctorResult(err))
+		if err == nil {
+			fmt.Fprintf(w, "  %-12s %s\n", "fingerprint", c.Fingerprint())
+		}
+	}
+	if failed {
+		return errors.New("one or more checks failed")
+	}
+	return nil
+}
+
+// checkRoundTrip ensures that a Cipher can decrypt its own output.
+func checkRoundTrip(c crypt.Cipher) error {
+	cipher, err := c.Encrypt("rskey doctor")
+	if err != nil {
+		return err
+	}
+	text, err := c.Decrypt(cipher)
+	if err != nil {
+		return err
+	}
+	if text != "rskey doctor" {
+		return crypt.ErrFailedToDecrypt
+	}
+	return nil
+}
+
+func doctorResult(err error) string {
+	if err != nil {
+		return "FAIL: " + err.Error()
+	}
+	return "ok"
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/config"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/edit"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit FILE",
+	Short: "Edit a configuration file with its secrets decrypted",
+	Long: `Open a configuration file in $VISUAL or $EDITOR with every value that the
+key can decrypt shown as plain text, and re-encrypt the values that changed when
+the editor exits. Unchanged values keep their original cipher text, and a
+decrypted value that is moved or renamed is encrypted again rather than saved
+as plain text.
+
+Supported files are gcfg (Connect and Package Manager), Workbench .conf, .env,
+JSON, YAML, and TOML. The type is detected from the file name unless --type is
+given. Workbench .conf files use the Workbench key and mode by default.
+
+The plain text is written to a private temporary file, on a memory-backed
+file system where one is available, which is overwritten and removed when the
+editor exits or rskey is terminated.
+
+Examples:
+  rskey edit -f /var/lib/rstudio-connect/db/secret.key /etc/rstudio-connect/rstudio-connect.gcfg
+  rskey edit /etc/rstudio/database.conf
+  EDITOR="code --wait" rskey edit -f secret.key values.yaml
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+// editCipher loads the key for a file in the given format. Workbench files use
+// the Workbench key and mode unless told otherwise.
+func editCipher(cmd *cobra.Command, use keyUse, format edit.Format) (crypt.Cipher, error) {
+	src := keySource{
+		keyfile:        cmd.Flag("keyfile").Value.String(),
+		label:          cmd.Flag("key").Value.String(),
+		mode:           cmd.Flag("mode").Value.String(),
+		explicitMode:   config.IsSet(cmd.Flag("mode")),
+		product:        cmd.Flag("product").Value.String(),
+		productVersion: cmd.Flag("product-version").Value.String(),
+		productKeyfile: true,
+		keystore:       cmd.Flag("keystore").Value.String(),
+		keyfileName:    "keyfile",
+		labelName:      "key",
+	}
+	if format == edit.Format(conffile.Workbench) && !src.explicitMode && src.product == "" {
+		src.product = "workbench"
+	}
+	return src.load(use)
+}
+
+// privateTempDir creates a directory readable only by its owner, preferring
+// memory-backed file systems so that plain text never reaches a disk.
+func privateTempDir() (string, error) {
+	candidates := []string{os.Getenv("XDG_RUNTIME_DIR"), "/dev/shm", os.TempDir()}
+	var err error
+	for _, dir := range candidates {
+		if dir == "" {
+			continue
+		}
+		var tmp string
+		if tmp, err = os.MkdirTemp(dir, "rskey-edit-"); err == nil {
+			return tmp, os.Chmod(tmp, 0700)
+		}
+	}
+	return "", err
+}
+
+// shred overwrites the regular files in dir, including any that an editor has
+// created alongside the one being edited, and then removes it.
+func shred(dir string) error {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			_ = os.WriteFile(path, make([]byte, info.Size()), 0600)
+		}
+		return nil
+	})
+	return os.RemoveAll(dir)
+}
+
+// editorCommand returns the user's preferred editor.
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if args := strings.Fields(os.Getenv(name)); len(args) > 0 {
+			return args
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	var format edit.Format
+	var err error
+	if t := cmd.Flag("type").Value.String(); t != "" {
+		format, err = edit.ParseFormat(t)
+	} else {
+		format, err = edit.DetectFormat(path)
+	}
+	if err != nil {
+		return err
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec, err := editCipher(cmd, useDecrypt, format)
+	if err != nil {
+		return err
+	}
+	enc, err := editCipher(cmd, useEncrypt, format)
+	if err != nil {
+		return err
+	}
+	session, view, err := edit.Open(format, original, dec)
+	if err != nil {
+		return err
+	}
+
+	dir, err := privateTempDir()
+	if err != nil {
+		return err
+	}
+	defer shred(dir)
+	// Keep the file name, so that editors recognize the file type.
+	tmp := filepath.Join(dir, filepath.Base(path))
+	if err := os.WriteFile(tmp, view, 0600); err != nil {
+		return err
+	}
+
+	// Interrupts are left to the editor, but termination removes the
+	// plain text before exiting.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer func() {
+		signal.Stop(sigs)
+		close(sigs)
+	}()
+	go func() {
+		for sig := range sigs {
+			if sig != os.Interrupt {
+				_ = shred(dir)
+				os.Exit(1)
+			}
+		}
+	}()
+
+	stdin := bufio.NewReader(cmd.InOrStdin())
+	for {
+		editor := editorCommand()
+		e := exec.Command(editor[0], append(editor[1:], tmp)...)
+		e.Stdin, e.Stdout, e.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := e.Run(); err != nil {
+			return fmt.Errorf("editor %s failed: %w", editor[0], err)
+		}
+		edited, err := os.ReadFile(tmp)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, view) {
+			fmt.Fprintln(cmd.ErrOrStderr(), "No changes")
+			return nil
+		}
+		out, changed, err := session.Seal(edited, enc)
+		if err == nil {
+			if err := conffile.WriteFile(path, out, conffile.ExistingPerm(path, 0600)); err != nil {
+				return err
+			}
+			noun := "values"
+			if len(changed) == 1 {
+				noun = "value"
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Saved %s, re-encrypting %d changed %s\n",
+				path, len(changed), noun)
+			return nil
+		}
+		// Give the user a chance to fix mistakes rather than losing
+		// their changes.
+		fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\nEdit again? [Y/n] ", err)
+		answer, readErr := stdin.ReadString('\n')
+		if readErr != nil || strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "n") {
+			return errors.New("no changes were saved")
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+	editCmd.Flags().String("type", "",
+		`The file type, such as "gcfg", "workbench", "dotenv", "json", "yaml", or "toml"`)
+}
@@ -13,7 +13,6 @@ import (
 	"golang.org/x/term"
 
 	"github.com/rstudio/rskey/crypt"
-	"github.com/rstudio/rskey/workbench"
 )
 
 var encryptCmd = &cobra.Command{
@@ -25,41 +24,33 @@ interactively. Line-separated data can also be passed on standard input.
 Examples:
   rskey encrypt -f /var/lib/rstudio-pm/rstudio-pm.key
   cat passwords.txt | rskey encrypt -f /var/lib/rstudio-pm/rstudio-pm.key
+
+Pass the existing cipher text with --previous to keep it unchanged when it
+already decrypts to the same data with the same key, so that repeated runs
+(e.g. in configuration management) produce the same output:
+
+  echo "$PASSWORD" | rskey encrypt -f rstudio-pm.key --previous="$OLD_VALUE"
+
+With --format=dotenv, standard input is a .env file and only the values of the
+variables given by --vars are encrypted, leaving names, comments, and quoting
+intact. With --format=csv, only the columns given by --columns are encrypted.
+Values that are already encrypted with the key are left alone:
+
+  rskey encrypt -f secret.key --format=dotenv --vars=DB_PASSWORD < .env
+  rskey encrypt -f secret.key --format=csv --columns=password,token < users.csv
 `,
 	RunE: runEncrypt,
 }
 
 func runEncrypt(cmd *cobra.Command, args []string) error {
-	keyfile := cmd.Flag("keyfile").Value.String()
-	if keyfile == "" {
-		return fmt.Errorf("keyfile is missing but must be provided")
-	}
-	f, err := os.Open(keyfile)
+	key, err := loadCipher(cmd, useEncrypt)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	var encrypt func(string) (string, error)
-	switch cmd.Flag("mode").Value.String() {
-	case "workbench":
-		key, err := workbench.NewKeyFromReader(f)
-		if err != nil {
-			return err
-		}
-		encrypt = key.Encrypt
-	case "fips":
-		key, err := crypt.NewKeyFromReader(f)
-		if err != nil {
-			return err
-		}
-		encrypt = key.EncryptFIPS
-	default:
-		key, err := crypt.NewKeyFromReader(f)
-		if err != nil {
-			return err
-		}
-		encrypt = key.Encrypt
+	if format := cmd.Flag("format").Value.String(); isStreamFormat(format) {
+		return transformStream(cmd, key, format, true)
 	}
+	previous, _ := cmd.Flags().GetStringArray("previous")
 	// Check if there's actually data in standard input.
 	info, err := os.Stdin.Stat()
 	if err != nil {
@@ -69,7 +60,7 @@ func runEncrypt(cmd *cobra.Command, args []string) error {
 	if info.Mode()&os.ModeNamedPipe != 0 {
 		scanner := bufio.NewScanner(os.Stdin)
 		for scanner.Scan() {
-			cipher, err := encrypt(scanner.Text())
+			cipher, err := crypt.EncryptStable(key, scanner.Text(), previous...)
 			if err != nil {
 				return err
 			}
@@ -80,36 +71,11 @@ func runEncrypt(cmd *cobra.Command, args []string) error {
 		}
 		return nil
 	}
-	// Temporarily put the terminal into raw mode so we can read data
-	// without echo.
-	data, err := func() (string, error) {
-		s, err := term.MakeRaw(int(os.Stdin.Fd()))
-		if err != nil {
-			return "", err
-		}
-		defer term.Restore(int(os.Stdin.Fd()), s)
-		terminal := term.NewTerminal(os.Stdin, "")
-		pass1, err := terminal.ReadPassword(
-			"Type the sensitive data to encrypt, then press Enter: ")
-		if err != nil {
-			return "", err
-		}
-		pass2, err := terminal.ReadPassword(
-			"Type the sensitive data again: ")
-		if err != nil {
-			return "", err
-		}
-
-		// Check to be sure that sensitive data entry was the same twice.
-		if pass1 != pass2 {
-			return "", errors.New("the two entries do not match")
-		}
-		return pass1, nil
-	}()
+	data, err := readSensitive()
 	if err != nil {
 		return err
 	}
-	cipher, err := encrypt(data)
+	cipher, err := crypt.EncryptStable(key, data, previous...)
 	if err != nil {
 		return err
 	}
@@ -117,9 +83,37 @@ func runEncrypt(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+// readSensitive reads sensitive data from the terminal, twice, without echo.
+func readSensitive() (string, error) {
+	// Temporarily put the terminal into raw mode so we can read data
+	// without echo.
+	s, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), s)
+	terminal := term.NewTerminal(os.Stdin, "")
+	pass1, err := terminal.ReadPassword(
+		"Type the sensitive data to encrypt, then press Enter: ")
+	if err != nil {
+		return "", err
+	}
+	pass2, err := terminal.ReadPassword(
+		"Type the sensitive data again: ")
+	if err != nil {
+		return "", err
+	}
+
+	// Check to be sure that sensitive data entry was the same twice.
+	if pass1 != pass2 {
+		return "", errors.New("the two entries do not match")
+	}
+	return pass1, nil
+}
+
 func init() {
 	rootCmd.AddCommand(encryptCmd)
-	encryptCmd.Flags().StringP("keyfile", "f", "", "Use the given key file")
-	encryptCmd.Flags().StringP("mode", "", "default",
-		`One of "default", "fips", or "workbench"`)
+	encryptCmd.Flags().StringArray("previous", nil,
+		"Keep this cipher text if it already matches (can be repeated)")
+	addStreamFlags(encryptCmd)
 }
@@ -5,12 +5,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/rstudio/rskey/crypt"
-	"github.com/rstudio/rskey/workbench"
 )
 
 var fingerprintCmd = &cobra.Command{
@@ -32,37 +28,14 @@ Examples:
 }
 
 func runFingerprint(cmd *cobra.Command, args []string) error {
-	keyfile := cmd.Flag("keyfile").Value.String()
-	if keyfile == "" {
-		return fmt.Errorf("keyfile is missing but must be provided")
-	}
-	f, err := os.Open(keyfile)
+	key, err := loadCipher(cmd, useAny)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	var fingerprint func() string
-	switch cmd.Flag("mode").Value.String() {
-	case "workbench":
-		key, err := workbench.NewKeyFromReader(f)
-		if err != nil {
-			return err
-		}
-		fingerprint = key.Fingerprint
-	default:
-		key, err := crypt.NewKeyFromReader(f)
-		if err != nil {
-			return err
-		}
-		fingerprint = key.Fingerprint
-	}
-	_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", fingerprint())
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s\n", key.Fingerprint())
 	return err
 }
 
 func init() {
 	rootCmd.AddCommand(fingerprintCmd)
-	fingerprintCmd.Flags().StringP("keyfile", "f", "", "Use the given key file")
-	fingerprintCmd.Flags().StringP("mode", "", "default",
-		`"default" or "workbench"`)
 }
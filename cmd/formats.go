@@ -0,0 +1,82 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/csvfile"
+)
+
+// isStreamFormat reports whether format is handled by transformStream.
+func isStreamFormat(format string) bool {
+	return format == "dotenv" || format == "csv"
+}
+
+// transformStream encrypts or decrypts the variables of a .env file, or the
+// columns of a CSV file, read from standard input. Values that are already
+// encrypted with the key are left alone when encrypting.
+func transformStream(cmd *cobra.Command, key crypt.Cipher, format string, encrypt bool) error {
+	f := key.Decrypt
+	if encrypt {
+		f = func(s string) (string, error) {
+			if _, err := key.Decrypt(s); err == nil {
+				return s, nil
+			}
+			return key.Encrypt(s)
+		}
+	}
+	if format == "csv" {
+		columns, _ := cmd.Flags().GetStringSlice("columns")
+		if len(columns) == 0 {
+			return errors.New("--columns must be provided with --format=csv")
+		}
+		_, err := csvfile.MapColumns(cmd.OutOrStdout(), cmd.InOrStdin(), columns, f)
+		return err
+	}
+	vars, _ := cmd.Flags().GetStringSlice("vars")
+	if len(vars) == 0 {
+		return errors.New("--vars must be provided with --format=dotenv")
+	}
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	doc, err := conffile.Parse(conffile.Dotenv, data)
+	if err != nil {
+		return err
+	}
+	for _, name := range vars {
+		value, ok := doc.Get(name)
+		if !ok {
+			return fmt.Errorf("variable %s is not set", name)
+		}
+		if value == "" {
+			continue
+		}
+		if value, err = f(value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := doc.Set(name, value); err != nil {
+			return err
+		}
+	}
+	_, err = cmd.OutOrStdout().Write(doc.Bytes())
+	return err
+}
+
+// addStreamFlags adds the flags used by transformStream.
+func addStreamFlags(cmd *cobra.Command) {
+	addFormatFlag(cmd, "text", "dotenv", "csv")
+	cmd.Flags().StringSlice("vars", nil,
+		"With --format=dotenv, the variables to transform, e.g. DB_PASSWORD,API_TOKEN")
+	cmd.Flags().StringSlice("columns", nil,
+		"With --format=csv, the columns to transform, e.g. password,token")
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/config"
+	"github.com/rstudio/rskey/gitfilter"
+	"github.com/rstudio/rskey/keystore"
+)
+
+var gitDiffTextconvCmd = &cobra.Command{
+	Use:   "git-diff-textconv FILE",
+	Short: "Show a file with encrypted values hashed, for git diff",
+	Long: `Print a file with every value the key can decrypt replaced by a hash of its
+plain text and the fingerprint of the key that encrypted it, so that git diff
+shows which secrets actually changed rather than random cipher text.
+
+The hash is keyed with the encryption key: it stays the same when a value is
+encrypted again or with another key in the keyring, but cannot be used to
+guess secrets by anyone without the key. With --plaintext, decrypted values
+are shown instead. Workbench values that cannot be decrypted are labelled with
+the checksum of their key.
+
+To use it, configure a diff driver and select files with .gitattributes:
+
+  git config diff.rskey.textconv "rskey git-diff-textconv -f /path/to/secret.key"
+  echo "*.gcfg diff=rskey" >> .gitattributes
+
+Examples:
+  rskey git-diff-textconv -f secret.key rstudio-connect.gcfg
+  rskey git-diff-textconv --key=connect --plaintext config.yml
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGitDiffTextconv,
+}
+
+func runGitDiffTextconv(cmd *cobra.Command, args []string) error {
+	key, err := loadCipher(cmd, useDecrypt)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	plaintext, _ := cmd.Flags().GetBool("plaintext")
+	out := gitfilter.Textconv(data, key, gitfilter.TextconvOptions{Plaintext: plaintext})
+	_, err = cmd.OutOrStdout().Write(out)
+	return err
+}
+
+var gitFilterCmd = &cobra.Command{
+	Use:   "git-filter",
+	Short: "Keep secrets encrypted in git but plain text in the working tree",
+	Long: `A git clean/smudge filter that stores values encrypted in the repository
+but shows them as plain text in the working tree.
+
+In the working tree, values to keep secret are written as DEC[...], with any
+backslash, closing bracket, or newline in them escaped with a backslash. The
+clean filter encrypts them as ENC[...] when files are added to git, and the
+smudge filter decrypts them again when files are checked out. Values whose
+plain text has not changed keep the cipher text already in the index, so that
+files do not appear modified just because they were encrypted again.
+
+Use "rskey git-filter install" to configure the filter for a repository.
+`,
+}
+
+var gitFilterCleanCmd = &cobra.Command{
+	Use:   "clean [FILE]",
+	Short: "Encrypt DEC[...] values on standard input",
+	Long: `Encrypt the DEC[...] values in a file read from standard input and write it
+to standard output, reusing cipher text from the copy of FILE in the git index
+where possible.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGitFilterClean,
+}
+
+var gitFilterSmudgeCmd = &cobra.Command{
+	Use:   "smudge [FILE]",
+	Short: "Decrypt ENC[...] values on standard input",
+	Long: `Decrypt the ENC[...] values in a file read from standard input and write it
+to standard output. Values that cannot be decrypted are left alone, with a
+warning. If the key cannot be loaded, the file is passed through unchanged, so
+that the repository can still be checked out without it.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGitFilterSmudge,
+}
+
+var gitFilterInstallCmd = &cobra.Command{
+	Use:   "install PATTERN...",
+	Short: "Configure the filter for the current repository",
+	Long: `Configure the rskey filter and diff drivers in the current repository's
+git configuration, using the key given by the key flags, and assign them to
+files matching each PATTERN in .gitattributes at the top of the repository.
+Key settings from RSKEY_* environment variables or a profile are written into
+the configuration, so the filter keeps using the same key.
+
+The filter is marked as required, so that git refuses to add files rather
+than storing plain text if encryption fails.
+
+Examples:
+  rskey git-filter install -f /path/to/secret.key '*.gcfg' config.yml
+  rskey git-filter install --key=connect '*.env'
+`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runGitFilterInstall,
+}
+
+func runGitFilterClean(cmd *cobra.Command, args []string) error {
+	key, err := loadCipher(cmd, useEncrypt)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	var previous []byte
+	if len(args) == 1 {
+		// The file may be new, or this may not be a repository at all.
+		previous, _ = exec.Command("git", "cat-file", "blob", ":"+args[0]).Output()
+	}
+	out, err := gitfilter.Clean(data, previous, key)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(out)
+	return err
+}
+
+func runGitFilterSmudge(cmd *cobra.Command, args []string) error {
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	name := "input"
+	if len(args) == 1 {
+		name = args[0]
+	}
+	// Failing would stop checkouts, and clones, for anyone without the key,
+	// so the file is checked out with its values still encrypted instead.
+	key, err := loadCipher(cmd, useDecrypt)
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s was not decrypted: %v\n", name, err)
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+	out, failed := gitfilter.Smudge(data, key)
+	if failed > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %d values in %s could not be decrypted\n", failed, name)
+	}
+	_, err = cmd.OutOrStdout().Write(out)
+	return err
+}
+
+// keyArgs returns the global flags that select a key, with the values they
+// were resolved to from the command line, the environment, or a profile, and
+// paths made absolute. The profile itself is left out, since its settings are
+// included, and the default keystore is given explicitly when a label is used,
+// so that the key does not depend on the environment git runs rskey in.
+func keyArgs(cmd *cobra.Command) ([]string, error) {
+	var out []string
+	for _, name := range []string{"keyfile", "key", "mode", "product", "product-version", "fips-policy", "policy", "keystore"} {
+		f := cmd.Flag(name)
+		value := f.Value.String()
+		if name == "keystore" && value == "" && cmd.Flag("key").Value.String() != "" {
+			dir, err := keystore.DefaultDir()
+			if err != nil {
+				return nil, err
+			}
+			value = dir
+		} else if !config.IsSet(f) {
+			continue
+		}
+		switch name {
+		case "keyfile", "policy", "keystore":
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				return nil, err
+			}
+			value = abs
+		}
+		out = append(out, "--"+name+"="+value)
+	}
+	return out, nil
+}
+
+// shellQuote quotes s for use in a command run by git through the shell.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_=./:@%+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func runGitFilterInstall(cmd *cobra.Command, args []string) error {
+	if _, err := loadCipher(cmd, useEncrypt); err != nil {
+		return err
+	}
+	flags, err := keyArgs(cmd)
+	if err != nil {
+		return err
+	}
+	command := func(args ...string) string {
+		words := append([]string{"rskey"}, args...)
+		words = append(words, flags...)
+		for i, w := range words {
+			words[i] = shellQuote(w)
+		}
+		return strings.Join(words, " ")
+	}
+	top, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+	config := [][2]string{
+		{"filter." + gitfilter.FilterName + ".clean", command("git-filter", "clean") + " -- %f"},
+		{"filter." + gitfilter.FilterName + ".smudge", command("git-filter", "smudge") + " -- %f"},
+		{"filter." + gitfilter.FilterName + ".required", "true"},
+		{"diff." + gitfilter.FilterName + ".textconv", command("git-diff-textconv")},
+	}
+	for _, kv := range config {
+		e := exec.Command("git", "config", "--local", kv[0], kv[1])
+		e.Stderr = cmd.ErrOrStderr()
+		if err := e.Run(); err != nil {
+			return fmt.Errorf("failed to set %s: %w", kv[0], err)
+		}
+	}
+	path := filepath.Join(string(bytes.TrimSpace(top)), ".gitattributes")
+	added, err := gitfilter.AddAttributes(path, args)
+	if err != nil {
+		return err
+	}
+	for _, p := range added {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Added %s to %s\n", p, path)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(gitDiffTextconvCmd)
+	gitDiffTextconvCmd.Flags().Bool("plaintext", false,
+		"Show decrypted values instead of hashes")
+	rootCmd.AddCommand(gitFilterCmd)
+	gitFilterCmd.AddCommand(gitFilterCleanCmd)
+	gitFilterCmd.AddCommand(gitFilterSmudgeCmd)
+	gitFilterCmd.AddCommand(gitFilterInstallCmd)
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/config"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/keystore"
+	"github.com/rstudio/rskey/product"
+	// Register the Workbench mode.
+	_ "github.com/rstudio/rskey/workbench"
+)
+
+// keyUse is what a command intends to do with a key, which determines which
+// keystore entries it may use.
+type keyUse int
+
+const (
+	// useAny allows any key that is not retired.
+	useAny keyUse = iota
+	// useEncrypt allows only active keys.
+	useEncrypt
+	// useDecrypt allows any key that is not retired, and falls back to
+	// the other keys in the keystore.
+	useDecrypt
+)
+
+// loadCipher reads the key given by the "keyfile" or "key" flag using the
+// loader registered for the "mode" flag. If the "product" flag is set, its
+// default key is used when neither is given.
+func loadCipher(cmd *cobra.Command, use keyUse) (crypt.Cipher, error) {
+	return loadCipherFrom(cmd, use, "keyfile", "key", "mode")
+}
+
+// loadCipherFrom is like loadCipher, but uses the given flags.
+func loadCipherFrom(cmd *cobra.Command, use keyUse, keyfileFlag, labelFlag, modeFlag string) (crypt.Cipher, error) {
+	src := keySource{
+		keyfile:        cmd.Flag(keyfileFlag).Value.String(),
+		label:          cmd.Flag(labelFlag).Value.String(),
+		mode:           cmd.Flag(modeFlag).Value.String(),
+		explicitMode:   config.IsSet(cmd.Flag(modeFlag)),
+		product:        cmd.Flag("product").Value.String(),
+		productVersion: cmd.Flag("product-version").Value.String(),
+		// Only the primary key defaults to the product's key file.
+		productKeyfile: keyfileFlag == "keyfile",
+		keystore:       cmd.Flag("keystore").Value.String(),
+		keyfileName:    keyfileFlag,
+		labelName:      labelFlag,
+	}
+	return src.load(use)
+}
+
+// keySource describes where to find a key, whether given by flags or by a
+// manifest.
+type keySource struct {
+	keyfile, label string
+	mode           string
+	// Whether the mode was given explicitly, rather than by default.
+	explicitMode            bool
+	product, productVersion string
+	// Whether the product's key file is used if no key is given.
+	productKeyfile bool
+	// The keystore directory, or empty for the default.
+	keystore string
+	// The names of the keyfile and label settings, for error messages.
+	keyfileName, labelName string
+}
+
+// load reads the key using the loader registered for its mode.
+func (s keySource) load(use keyUse) (crypt.Cipher, error) {
+	keyfile, label, mode, explicit := s.keyfile, s.label, s.mode, s.explicitMode
+	if keyfile != "" && label != "" {
+		return nil, fmt.Errorf("only one of %s or %s can be provided", s.keyfileName, s.labelName)
+	}
+	var store *keystore.Store
+	if label != "" {
+		var err error
+		store, err = openKeystoreDir(s.keystore)
+		if err != nil {
+			return nil, err
+		}
+		// Keys in the keystore know their own mode.
+		if !explicit {
+			entry, err := store.Get(label)
+			if err != nil {
+				return nil, err
+			}
+			mode, explicit = entry.Mode, true
+		}
+	}
+	p, v, err := lookupProduct(s.product, s.productVersion)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		if keyfile == "" && label == "" && s.productKeyfile {
+			keyfile = p.KeyPath
+		}
+		if !explicit {
+			mode = p.Mode(v)
+		}
+		// Only encryption has to produce something the product can
+		// decrypt.
+		if use == useEncrypt {
+			mode, err = p.Choose(mode, v, crypt.EncryptsSecretbox())
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if store != nil {
+		switch use {
+		case useEncrypt:
+			return store.EncryptCipher(label, mode)
+		case useDecrypt:
+			return store.DecryptCipher(label, mode)
+		}
+		return store.Cipher(label, mode)
+	}
+	if keyfile == "" {
+		return nil, fmt.Errorf("%s is missing but must be provided", s.keyfileName)
+	}
+	f, err := os.Open(keyfile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return crypt.LoadCipher(mode, f)
+}
+
+// lookupProduct returns the product with the given name and version, or nil if
+// name is empty.
+func lookupProduct(name, version string) (*product.Product, product.Version, error) {
+	v, err := product.ParseVersion(version)
+	if err != nil || name == "" {
+		return nil, v, err
+	}
+	p, err := product.Lookup(name)
+	return p, v, err
+}
+
+// openKeystore opens the keystore given by the "keystore" flag.
+func openKeystore(cmd *cobra.Command) (*keystore.Store, error) {
+	return openKeystoreDir(cmd.Flag("keystore").Value.String())
+}
+
+// openKeystoreDir opens the keystore in dir, or the default keystore if dir is
+// empty.
+func openKeystoreDir(dir string) (*keystore.Store, error) {
+	if dir == "" {
+		var err error
+		dir, err = keystore.DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keystore.Open(dir)
+}
+
+// modeUsage lists the registered modes for use in flag help text.
+func modeUsage() string {
+	names := []string{}
+	for _, m := range crypt.Modes() {
+		names = append(names, m.Name)
+	}
+	return oneOf(names)
+}
+
+// productUsage lists the known products for use in flag help text.
+func productUsage() string {
+	return oneOf(product.Names())
+}
+
+// oneOf lists the given choices for use in flag help text.
+func oneOf(choices []string) string {
+	names := []string{}
+	for _, name := range choices {
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return "One of " + names[0] + " or " + names[1]
+	}
+	return "One of " + strings.Join(names[:len(names)-1], ", ") +
+		", or " + names[len(names)-1]
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/keystore"
+	"github.com/rstudio/rskey/workbench"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage keys in a local keystore",
+	Long: `Manage a directory of labelled keys, each with a lifecycle state:
+
+  active        used for encryption and decryption
+  decrypt-only  used only for decryption
+  retired       never used
+
+Other commands accept --key=LABEL in place of --keyfile. Encryption only uses
+active keys, and decryption falls back to any other key that is not retired.
+
+The keystore is ~/.config/rskey/keys unless --keystore is given.
+
+Examples:
+  rskey keys add connect-2026 -f /var/lib/rstudio-connect/db/secret.key
+  rskey keys add workbench --mode=workbench -f /etc/rstudio/secure-cookie-key
+  rskey keys add connect-2027 --generate
+  rskey keys list
+  rskey keys retire connect-2026
+  rskey keys remove connect-2026
+`,
+}
+
+var keysAddCmd = &cobra.Command{
+	Use:   "add LABEL",
+	Short: "Add a key to the keystore",
+	Long: `Add a key file, or a newly-generated key, to the keystore. Adding an
+active key makes other active keys with the same mode decrypt-only.
+
+The key file and mode are given by --keyfile and --mode, so they can also come
+from a profile or the RSKEY_KEYFILE and RSKEY_MODE environment variables. With
+--generate, a key file from a profile or the environment is ignored, and the new
+key has the format of the mode: a UUID for Workbench, and a Connect/Package
+Manager key otherwise.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runKeysAdd,
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the keys in the keystore",
+	Args:  cobra.NoArgs,
+	RunE:  runKeysList,
+}
+
+var keysRetireCmd = &cobra.Command{
+	Use:   "retire LABEL",
+	Short: "Stop using a key",
+	Long: `Retire a key so that it is no longer used, or with --decrypt-only, so
+that it is only used for decryption.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runKeysRetire,
+}
+
+var keysRemoveCmd = &cobra.Command{
+	Use:   "remove LABEL",
+	Short: "Delete a retired key from the keystore",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runKeysRemove,
+}
+
+func runKeysAdd(cmd *cobra.Command, args []string) error {
+	store, err := openKeystore(cmd)
+	if err != nil {
+		return err
+	}
+	state, err := keystore.ParseState(cmd.Flag("state").Value.String())
+	if err != nil {
+		return err
+	}
+	keyfile := cmd.Flag("keyfile").Value.String()
+	generate, _ := cmd.Flags().GetBool("generate")
+	mode := cmd.Flag("mode").Value.String()
+	var data []byte
+	switch {
+	case generate && cmd.Flag("keyfile").Changed:
+		return errors.New("only one of keyfile or generate can be provided")
+	case generate && mode == "workbench":
+		// A key file from a profile or the environment is ignored.
+		data, err = workbench.GenerateKey()
+		if err != nil {
+			return err
+		}
+	case generate:
+		key, err := crypt.NewKey()
+		if err != nil {
+			return err
+		}
+		data = []byte(key.HexString())
+	case keyfile != "":
+		data, err = os.ReadFile(keyfile)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("keyfile is missing but must be provided")
+	}
+	entry, err := store.Add(args[0], mode, data, state)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Added %s key %s (%s) as %s\n",
+		entry.Mode, entry.Label, entry.Fingerprint, entry.State)
+	return err
+}
+
+func runKeysList(cmd *cobra.Command, args []string) error {
+	store, err := openKeystore(cmd)
+	if err != nil {
+		return err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+	switch format := cmd.Flag("format").Value.String(); format {
+	case "json":
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "text":
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LABEL\tMODE\tSTATE\tCREATED\tFINGERPRINT")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Label, e.Mode, e.State,
+			e.Created.Format(time.DateOnly), e.Fingerprint)
+	}
+	return w.Flush()
+}
+
+func runKeysRetire(cmd *cobra.Command, args []string) error {
+	store, err := openKeystore(cmd)
+	if err != nil {
+		return err
+	}
+	state := keystore.Retired
+	if decryptOnly, _ := cmd.Flags().GetBool("decrypt-only"); decryptOnly {
+		state = keystore.DecryptOnly
+	}
+	if err := store.SetState(args[0], state); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Key %s is now %s\n", args[0], state)
+	return err
+}
+
+func runKeysRemove(cmd *cobra.Command, args []string) error {
+	store, err := openKeystore(cmd)
+	if err != nil {
+		return err
+	}
+	entry, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+	if force, _ := cmd.Flags().GetBool("force"); entry.State != keystore.Retired && !force {
+		return fmt.Errorf("key %s is %s; retire it first or pass --force", entry.Label, entry.State)
+	}
+	if err := store.Remove(entry.Label); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Removed key %s\n", entry.Label)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysAddCmd, keysListCmd, keysRetireCmd, keysRemoveCmd)
+	keysAddCmd.Flags().Bool("generate", false, "Add a newly-generated key")
+	keysAddCmd.Flags().String("state", string(keystore.Active),
+		`One of "active", "decrypt-only", or "retired"`)
+	keysRetireCmd.Flags().Bool("decrypt-only", false,
+		"Keep using the key for decryption")
+	keysRemoveCmd.Flags().Bool("force", false, "Remove the key even if it is not retired")
+	addFormatFlag(keysListCmd, "text", "json")
+}
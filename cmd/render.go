@@ -0,0 +1,98 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/render"
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render TEMPLATE",
+	Short: "Render a configuration file template, encrypting secrets",
+	Long: `Render a Go text/template to standard output, or a given output file,
+with functions that encrypt values as they are rendered:
+
+  encrypt PLAINTEXT           encrypt with the key
+  encryptFIPS PLAINTEXT       encrypt with the key, using AES-256-GCM
+  workbenchEncrypt PLAINTEXT  encrypt with the --workbench-keyfile key
+  fingerprint                 the fingerprint of the key
+  env NAME                    an environment variable, which must be set
+  file PATH                   the contents of a file, without a trailing newline
+
+Plain text secrets are never written anywhere except the rendered output, and
+nothing is written if rendering fails. Output files are only readable by their
+owner. Pass "-" to read the template from standard input.
+
+Examples:
+  rskey render -f /var/lib/rstudio-connect/db/secret.key \
+    rstudio-connect.gcfg.tmpl > rstudio-connect.gcfg
+
+where rstudio-connect.gcfg.tmpl contains, for example:
+
+  [Postgres]
+  Password = {{ env "DB_PASSWORD" | encrypt }}
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRender,
+}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	r := &render.Renderer{}
+	if cmd.Flag("keyfile").Value.String() != "" || cmd.Flag("key").Value.String() != "" ||
+		cmd.Flag("product").Value.String() != "" {
+		c, err := loadCipher(cmd, useEncrypt)
+		if err != nil {
+			return err
+		}
+		r.Cipher = c
+	}
+	if path := cmd.Flag("workbench-keyfile").Value.String(); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r.Workbench, err = crypt.LoadCipher("workbench", f)
+		if err != nil {
+			return err
+		}
+	}
+	var text []byte
+	var err error
+	if args[0] == "-" {
+		text, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		text, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := r.Render(&buf, filepath.Base(args[0]), string(text)); err != nil {
+		return err
+	}
+	outfile := cmd.Flag("output").Value.String()
+	if outfile == "" {
+		_, err = buf.WriteTo(cmd.OutOrStdout())
+		return err
+	}
+	return conffile.WriteFile(outfile, buf.Bytes(), 0600)
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+	renderCmd.Flags().StringP("output", "o", "",
+		"Write to this file instead of standard output")
+	renderCmd.Flags().String("workbench-keyfile", "",
+		"Use this Workbench key for workbenchEncrypt")
+}
@@ -4,9 +4,15 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/rstudio/rskey/config"
+	"github.com/rstudio/rskey/crypt"
 )
 
 var (
@@ -17,6 +23,90 @@ var rootCmd = &cobra.Command{
 	Use:     "rskey",
 	Short:   "Manage keys and secrets for Posit Connect and Package Manager",
 	Version: Version,
+	Long: `Manage keys and secrets for Posit Connect, Package Manager, and Workbench.
+
+Flags such as --keyfile and --mode can be given defaults by named profiles in
+/etc/rskey/config.toml or ~/.config/rskey/config.toml, for example:
+
+  default_profile = "ppm"
+
+  [profiles.ppm]
+  keyfile = "/var/lib/rstudio-pm/rstudio-pm.key"
+  mode = "fips"
+
+Each of these flags can also be set with an environment variable, such as
+RSKEY_KEYFILE or RSKEY_FIPS_POLICY, which takes precedence over the profile.
+Only the --keyfile or --key setting with the highest precedence is used.
+
+A crypto policy in /etc/rskey/policy.json can restrict the algorithms, cipher
+text formats, and keys that every command may use. A policy given by --policy
+(or a profile) can tighten the system policy, but never relax it.
+
+With --product, the product's default key file is used, and encryption uses
+the strongest mode that --product-version can decrypt.
+`,
+	PersistentPreRunE: runRoot,
+}
+
+func runRoot(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(config.DefaultPaths()...)
+	if err != nil {
+		return err
+	}
+	name := cmd.Flag("profile").Value.String()
+	if !cmd.Flag("profile").Changed {
+		name = os.Getenv(config.EnvName("profile"))
+	}
+	profile, err := cfg.Profile(name)
+	if err != nil {
+		return err
+	}
+	// Only the global flags and --format, which each command that supports
+	// it has its own choices for, can be given defaults.
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	flags.AddFlagSet(cmd.Root().PersistentFlags())
+	if f := cmd.LocalNonPersistentFlags().Lookup("format"); f != nil {
+		flags.AddFlag(f)
+	}
+	if err := config.Apply(flags, profile, os.Getenv); err != nil {
+		return err
+	}
+	policy, err := crypt.ParseFIPSPolicy(cmd.Flag("fips-policy").Value.String())
+	if err != nil {
+		return err
+	}
+	crypt.SetFIPSPolicy(policy)
+	return loadPolicy(cmd)
+}
+
+// loadPolicy applies the system crypto policy, if it exists, and the policy
+// given by the "policy" flag. The latter can only tighten the former.
+func loadPolicy(cmd *cobra.Command) error {
+	p, err := crypt.LoadPolicyFile(config.SystemPolicyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		p = nil
+	} else if err != nil {
+		return err
+	}
+	if path := cmd.Flag("policy").Value.String(); path != "" {
+		extra, err := crypt.LoadPolicyFile(path)
+		if err != nil {
+			return err
+		}
+		if p, err = p.Intersect(extra); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if p == nil {
+		return nil
+	}
+	return crypt.SetPolicy(p)
+}
+
+// addFormatFlag adds a --format flag to a command that accepts the given output
+// formats, the first of which is the default.
+func addFormatFlag(cmd *cobra.Command, formats ...string) {
+	cmd.Flags().String("format", formats[0], oneOf(formats))
 }
 
 // Execute runs the rskey command. On error it will call os.Exit.
@@ -26,3 +116,22 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+func init() {
+	rootCmd.PersistentFlags().String("profile", "",
+		"Use defaults from this profile in the configuration file")
+	rootCmd.PersistentFlags().StringP("keyfile", "f", "", "Use the given key file")
+	rootCmd.PersistentFlags().StringP("key", "k", "",
+		"Use the key with this label in the keystore")
+	rootCmd.PersistentFlags().String("mode", "default", modeUsage())
+	rootCmd.PersistentFlags().String("product", "",
+		productUsage()+", to use its default key and supported modes")
+	rootCmd.PersistentFlags().String("product-version", "",
+		"The product version, such as 2024.04.0, to choose the strongest mode it supports")
+	rootCmd.PersistentFlags().String("fips-policy", "auto",
+		`Either "auto" or "enforce" to refuse non-FIPS algorithms`)
+	rootCmd.PersistentFlags().String("policy", "",
+		"Enforce this crypto policy file in addition to "+config.SystemPolicyPath)
+	rootCmd.PersistentFlags().String("keystore", "",
+		"Use this keystore directory instead of ~/.config/rskey/keys")
+}
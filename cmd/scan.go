@@ -0,0 +1,237 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/scan"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [PATH...]",
+	Short: "Find unencrypted secrets and keys in configuration files",
+	Long: `Scan configuration files for sensitive settings that should be encrypted but
+are not, such as Postgres.Password in rstudio-connect.gcfg or password in
+Workbench's database.conf. Directories are searched recursively, and the
+current directory is scanned by default.
+
+Given a key with --keyfile or --key, encrypted values are also checked to make
+sure that they can be decrypted with it. Workbench keys are used for Workbench
+files, and other keys for Connect and Package Manager files. Use --product to
+only scan one product's files.
+
+With --keys, every file is searched for key material instead: Connect and
+Package Manager keys in hex or base64, and Workbench keys in secure-cookie-key
+files, copies of them such as secure-cookie-key.bak, and files holding only a
+UUID.
+Given a key with --keyfile or --key, every cipher text that it can decrypt is
+listed too, to show what was exposed if that key leaked.
+
+With --staged, the files staged in the git index are scanned rather than the
+working tree, and any PATH arguments are treated as git pathspecs. This is
+suitable for a pre-commit hook.
+
+Results are printed as text, or with --format=json or --format=sarif for code
+scanning tools. The command exits with a non-zero status if it finds any
+problems; cipher text that the key can decrypt is only listed.
+
+Examples:
+  rskey scan /etc/rstudio-connect /etc/rstudio
+  rskey scan -f /var/lib/rstudio-connect/db/secret.key --product=connect config/
+  rskey scan --format=sarif > rskey.sarif
+  rskey scan --keys --staged
+  rskey scan --keys -f leaked.key --format=json
+`,
+	RunE: runScan,
+}
+
+// loadScanKey loads the key given by the "keyfile" or "key" flags, or returns
+// nil if neither is given.
+func loadScanKey(cmd *cobra.Command) (crypt.Cipher, error) {
+	if cmd.Flag("keyfile").Value.String() == "" && cmd.Flag("key").Value.String() == "" {
+		return nil, nil
+	}
+	return loadCipher(cmd, useDecrypt)
+}
+
+// maxScanSize is the size of the largest file that is scanned.
+const maxScanSize = 10 << 20
+
+// walkFiles calls fn with the contents of every file under paths for which
+// want returns true, skipping version control directories and large files.
+func walkFiles(paths []string, want func(path string) bool, fn func(path string, data []byte) error) error {
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && (d.Name() == ".git" || d.Name() == ".hg" || d.Name() == ".svn") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || !want(path) {
+				return nil
+			}
+			if info, err := d.Info(); err != nil || info.Size() > maxScanSize {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return fn(path, data)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stagedFiles is like walkFiles, but uses the contents of the files staged in
+// the git index that were added or changed, limited to the given pathspecs.
+// Paths are relative to the top of the repository.
+func stagedFiles(pathspecs []string, want func(path string) bool, fn func(path string, data []byte) error) error {
+	args := append([]string{"diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR", "--"}, pathspecs...)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return fmt.Errorf("failed to list staged files: %w", err)
+	}
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path == "" || !want(path) {
+			continue
+		}
+		data, err := exec.Command("git", "cat-file", "blob", ":"+path).Output()
+		if err != nil {
+			return fmt.Errorf("failed to read staged %s: %w", path, err)
+		}
+		if len(data) > maxScanSize {
+			continue
+		}
+		if err := fn(path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runScan(cmd *cobra.Command, args []string) error {
+	format := cmd.Flag("format").Value.String()
+	if format != "text" && format != "json" && format != "sarif" {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	key, err := loadScanKey(cmd)
+	if err != nil {
+		return err
+	}
+	var (
+		want     func(path string) bool
+		scanFile func(path string, data []byte) ([]scan.Finding, error)
+	)
+	if keys, _ := cmd.Flags().GetBool("keys"); keys {
+		scanner := &scan.KeyScanner{Key: key}
+		want = func(string) bool { return true }
+		scanFile = func(path string, data []byte) ([]scan.Finding, error) {
+			if scan.IsBinary(data) {
+				return nil, nil
+			}
+			return scanner.ScanFile(path, data), nil
+		}
+	} else {
+		scanner := &scan.Scanner{}
+		if key != nil {
+			// Workbench keys are used for Workbench files, and other
+			// keys for everything else.
+			scanner.Key = func(product string) crypt.Cipher {
+				if (product == "workbench") == (key.Mode() == "workbench") {
+					return key
+				}
+				return nil
+			}
+		}
+		name := cmd.Flag("product").Value.String()
+		for _, s := range scan.Catalog() {
+			if name == "" || s.Product == name {
+				scanner.Settings = append(scanner.Settings, s)
+			}
+		}
+		if len(scanner.Settings) == 0 {
+			return fmt.Errorf("no sensitive settings are known for product %q", name)
+		}
+		want = scanner.Matches
+		scanFile = scanner.ScanFile
+	}
+	findings := []scan.Finding{}
+	visit := func(path string, data []byte) error {
+		found, err := scanFile(path, data)
+		findings = append(findings, found...)
+		return err
+	}
+	if staged, _ := cmd.Flags().GetBool("staged"); staged {
+		err = stagedFiles(args, want, visit)
+	} else {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		err = walkFiles(args, want, visit)
+	}
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(findings)
+	case "sarif":
+		err = scan.WriteSARIF(w, findings, Version)
+	default:
+		for _, f := range findings {
+			if _, err = fmt.Fprintf(w, "%s:%d: %s: %s (%s)\n", f.Path, f.Line, f.Level(), f.Message, f.Rule); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	problems := 0
+	for _, f := range findings {
+		if f.Level() == scan.LevelError {
+			problems++
+		}
+	}
+	if problems > 0 {
+		// Findings are not a usage error.
+		cmd.SilenceUsage = true
+		noun := "problems"
+		if problems == 1 {
+			noun = "problem"
+		}
+		return fmt.Errorf("found %d %s", problems, noun)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+	addFormatFlag(scanCmd, "text", "json", "sarif")
+	scanCmd.Flags().Bool("keys", false,
+		"Look for keys instead, and cipher text that the key given can decrypt")
+	scanCmd.Flags().Bool("staged", false,
+		"Scan the files staged in the git index, e.g. in a pre-commit hook")
+}
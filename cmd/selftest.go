@@ -0,0 +1,121 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/selftest"
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run known-answer tests for every encryption mode",
+	Long: `Check that this build of rskey still produces and accepts the exact
+formats used by Posit Connect, Package Manager, and Workbench, using an
+embedded corpus of fixed keys, plain texts, and cipher texts. Every registered
+mode is also checked with pairwise round trips.
+
+A report is printed on standard output, and the command exits with a non-zero
+status if any check fails. In FIPS mode, algorithms that are not FIPS-approved
+are expected to be refused.
+
+The report can be signed off with --signed-off-by, and signed with the Ed25519
+private key in the PKCS #8 PEM file given by --sign-key, such as one created by
+"openssl genpkey -algorithm ed25519". Use --verify to check the signature on a
+saved report; the public key that signed it is printed.
+
+Examples:
+  rskey selftest
+  GODEBUG=fips140=on rskey selftest
+  rskey selftest --signed-off-by="Jane Doe" --sign-key=audit.pem > report.txt
+  rskey selftest --verify=report.txt
+`,
+	Args: cobra.NoArgs,
+	RunE: runSelftest,
+}
+
+// readSignKey reads an Ed25519 private key from a PKCS #8 PEM file.
+func readSignKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: no PKCS #8 private key found", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 private key", path)
+	}
+	return ed, nil
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	if path := cmd.Flag("verify").Value.String(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pub, err := selftest.VerifyReport(data)
+		if err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "Good signature by ed25519 %s\n",
+			base64.StdEncoding.EncodeToString(pub))
+		return err
+	}
+	signOff := selftest.SignOff{Name: cmd.Flag("signed-off-by").Value.String()}
+	if path := cmd.Flag("sign-key").Value.String(); path != "" {
+		key, err := readSignKey(path)
+		if err != nil {
+			return err
+		}
+		signOff.Key = key
+	}
+	report, err := selftest.Run()
+	if err != nil {
+		return err
+	}
+	var header strings.Builder
+	fmt.Fprintf(&header, "rskey %s self-test, %s\n", Version,
+		time.Now().UTC().Format(time.RFC3339))
+	if err := writeCryptoInfo(&header); err != nil {
+		return err
+	}
+	if err := report.WriteSigned(cmd.OutOrStdout(), header.String(), signOff); err != nil {
+		return err
+	}
+	if n := report.Failures(); n > 0 {
+		return fmt.Errorf("%d self-test checks failed", n)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().String("signed-off-by", "",
+		"Record who signs off the report")
+	selftestCmd.Flags().String("sign-key", "",
+		"Sign the report with the Ed25519 private key in this PEM file")
+	selftestCmd.Flags().String("verify", "",
+		"Check the signature on a saved report instead of running the tests")
+	selftestCmd.MarkFlagsMutuallyExclusive("verify", "sign-key")
+	selftestCmd.MarkFlagsMutuallyExclusive("verify", "signed-off-by")
+}
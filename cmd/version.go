@@ -0,0 +1,79 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and cryptographic module information",
+	Long: `Print the version of rskey, along with the cryptographic module and
+FIPS mode currently in use.
+
+Examples:
+  rskey version
+  GODEBUG=fips140=on rskey version
+`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	_, err := fmt.Fprintf(cmd.OutOrStdout(), "rskey %s\n", Version)
+	if err != nil {
+		return err
+	}
+	return writeCryptoInfo(cmd.OutOrStdout())
+}
+
+// writeCryptoInfo describes the cryptographic module and FIPS mode in use.
+func writeCryptoInfo(w io.Writer) error {
+	status := crypt.CurrentFIPSStatus()
+	module := "Go standard library"
+	if status.Module {
+		module = "Go Cryptographic Module (FIPS 140-3)"
+	}
+	if status.ModuleVersion != "" && status.ModuleVersion != "off" {
+		module += ", GOFIPS140=" + status.ModuleVersion
+	}
+	mode := "disabled"
+	if status.Enabled {
+		reasons := []string{}
+		if status.BuildTag {
+			reasons = append(reasons, "fips build tag")
+		}
+		if status.Module {
+			reasons = append(reasons, "fips140 module enabled")
+		}
+		if status.Policy == crypt.FIPSEnforce {
+			reasons = append(reasons, "enforce policy")
+		}
+		mode = fmt.Sprintf("enabled (%s)", strings.Join(reasons, ", "))
+	}
+	policy := "none"
+	if crypt.CurrentPolicy() != nil {
+		policy = "enforced"
+	}
+	_, err := fmt.Fprintf(w, "Go version:           %s %s/%s\n"+
+		"Cryptographic module: %s\n"+
+		"FIPS mode:            %s\n"+
+		"FIPS policy:          %s\n"+
+		"Crypto policy:        %s\n",
+		runtime.Version(), runtime.GOOS, runtime.GOARCH, module, mode,
+		status.Policy, policy)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
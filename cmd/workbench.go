@@ -0,0 +1,351 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/product"
+	"github.com/rstudio/rskey/workbench"
+)
+
+var workbenchCmd = &cobra.Command{
+	Use:   "workbench",
+	Short: "Tools specific to Posit Workbench",
+}
+
+var workbenchCheckKeyCmd = &cobra.Command{
+	Use:   "check-key",
+	Short: "Check the strength of a Workbench key",
+	Long: `Estimate the strength of a Workbench secure-cookie-key, and report
+anything that makes it weak, such as a time-based (version 1) UUID, a short or
+repetitive key, a phrase or common words, or one made of few distinct
+characters. The entropy estimate accounts for common words and passwords, and
+for parts of the key that repeat.
+
+The key is read from --keyfile, --key, or /etc/rstudio/secure-cookie-key. The
+command exits with a non-zero status if the key is weak.
+
+Examples:
+  rskey workbench check-key
+  rskey workbench check-key -f uuid.key --format=json
+`,
+	Args: cobra.NoArgs,
+	RunE: runWorkbenchCheckKey,
+}
+
+func runWorkbenchCheckKey(cmd *cobra.Command, args []string) error {
+	data, err := readKeyData(cmd)
+	if err != nil {
+		return err
+	}
+	a := workbench.Analyze(data)
+	w := cmd.OutOrStdout()
+	switch format := cmd.Flag("format").Value.String(); format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(a)
+	case "text":
+		fmt.Fprintf(w, "Length:   %d bytes\nEntropy:  ~%.0f bits\nDistinct: %d characters\n",
+			a.Length, a.Entropy, a.Distinct)
+		if a.UUIDVersion != 0 {
+			fmt.Fprintf(w, "UUID:     version %d\n", a.UUIDVersion)
+		}
+		for _, f := range a.Findings {
+			fmt.Fprintf(w, "%-8s  %s\n", f.Severity, f.Message)
+		}
+		result := "OK"
+		if a.Weak() {
+			result = "WEAK"
+		}
+		_, err = fmt.Fprintf(w, "Result:   %s\n", result)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	if a.Weak() {
+		// The result has already been reported in full.
+		cmd.SilenceUsage = true
+		return errors.New("the key is weak and should be replaced")
+	}
+	return nil
+}
+
+// readKeyData returns the raw contents of the key given by the "keyfile" or
+// "key" flags, or the Workbench key file by default.
+func readKeyData(cmd *cobra.Command) ([]byte, error) {
+	keyfile := cmd.Flag("keyfile").Value.String()
+	label := cmd.Flag("key").Value.String()
+	switch {
+	case keyfile != "" && label != "":
+		return nil, errors.New("only one of keyfile or key can be provided")
+	case label != "":
+		store, err := openKeystore(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return store.KeyData(label)
+	case keyfile == "":
+		p, _ := product.Lookup("workbench")
+		keyfile = p.KeyPath
+	}
+	return os.ReadFile(keyfile)
+}
+
+var workbenchConfCmd = &cobra.Command{
+	Use:   "conf",
+	Short: "Edit encrypted settings in Workbench configuration files",
+	Long: `Set, show, and verify encrypted settings in Workbench's key=value
+configuration files, such as /etc/rstudio/database.conf. Comments and layout
+are preserved.
+
+The key is read from --keyfile, --key, or /etc/rstudio/secure-cookie-key.
+
+Examples:
+  echo "$DB_PASSWORD" | rskey workbench conf set /etc/rstudio/database.conf password
+  rskey workbench conf show /etc/rstudio/database.conf
+  rskey workbench conf verify /etc/rstudio/database.conf
+`,
+}
+
+var workbenchConfSetCmd = &cobra.Command{
+	Use:   "set FILE NAME",
+	Short: "Encrypt and set a setting",
+	Long: `Encrypt a value and set it as the named setting, creating the file if
+necessary. The value is read from the first line of standard input, or typed
+interactively, and must not be empty. The existing value is kept if it already
+matches.
+
+Workbench requires that files with encrypted settings are only readable by
+their owner, so the file's permissions are changed to 0600 if necessary.
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: runWorkbenchConfSet,
+}
+
+var workbenchConfShowCmd = &cobra.Command{
+	Use:   "show FILE",
+	Short: "Show settings, decrypting and masking encrypted values",
+	Long: `Show the settings in a file. Encrypted values, and values of settings that
+look sensitive, are masked unless --reveal is given.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWorkbenchConfShow,
+}
+
+var workbenchConfVerifyCmd = &cobra.Command{
+	Use:   "verify FILE",
+	Short: "Check that encrypted settings match the key",
+	Long: `Check that the key checksum embedded in each encrypted value matches the
+fingerprint of the key, so that Workbench will be able to decrypt it. The
+command exits with a non-zero status if any value does not match.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWorkbenchConfVerify,
+}
+
+// workbenchKey reads the key given by the "keyfile" or "key" flags, or the
+// Workbench key file by default.
+func workbenchKey(cmd *cobra.Command) (*workbench.Key, error) {
+	data, err := readKeyData(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return workbench.NewKeyFromBytes(data)
+}
+
+func runWorkbenchConfSet(cmd *cobra.Command, args []string) error {
+	path, name := args[0], args[1]
+	key, err := workbenchKey(cmd)
+	if err != nil {
+		return err
+	}
+	doc, err := conffile.ReadFile(path, conffile.Workbench)
+	if errors.Is(err, fs.ErrNotExist) {
+		doc, err = conffile.Parse(conffile.Workbench, nil)
+	}
+	if err != nil {
+		return err
+	}
+	var value string
+	in := cmd.InOrStdin()
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if value, err = readSensitive(); err != nil {
+			return err
+		}
+	} else {
+		scanner := bufio.NewScanner(in)
+		if scanner.Scan() {
+			value = scanner.Text()
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+	// An unset variable in `echo "$VAR" |` must not become an empty secret.
+	if value == "" {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("no value for %s was given", name)
+	}
+	previous, _ := doc.Get(name)
+	cipher, err := crypt.EncryptStable(key, value, previous)
+	if err != nil {
+		return err
+	}
+	if cipher == previous {
+		_, err = fmt.Fprintf(cmd.ErrOrStderr(), "%s is unchanged\n", name)
+		return err
+	}
+	if err := doc.Set(name, cipher); err != nil {
+		return err
+	}
+	// Workbench requires that files with encrypted settings are only
+	// readable by their owner.
+	if perm := conffile.ExistingPerm(path, 0600); perm != 0600 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Changing the permissions of %s from %#o to 0600\n", path, perm)
+	}
+	if err := conffile.WriteFile(path, doc.Bytes(), 0600); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.ErrOrStderr(), "Set %s, encrypted with key %s\n", name,
+		key.Fingerprint())
+	return err
+}
+
+// confSetting is a setting shown by "rskey workbench conf show".
+type confSetting struct {
+	Name      string `json:"name"`
+	Line      int    `json:"line"`
+	Value     string `json:"value"`
+	Encrypted bool   `json:"encrypted"`
+	// The checksum of the key that encrypted the value.
+	Checksum string `json:"checksum,omitempty"`
+	// Whether the value could be decrypted with the key.
+	Decrypted bool `json:"decrypted"`
+}
+
+// sensitiveName matches the names of settings that usually hold secrets.
+var sensitiveName = regexp.MustCompile(`(?i)pass|secret|token|key|credential`)
+
+// mask hides every character of a value, showing only its length.
+func mask(s string) string {
+	return strings.Repeat("*", utf8.RuneCountInString(s))
+}
+
+func runWorkbenchConfShow(cmd *cobra.Command, args []string) error {
+	key, err := workbenchKey(cmd)
+	if err != nil {
+		return err
+	}
+	doc, err := conffile.ReadFile(args[0], conffile.Workbench)
+	if err != nil {
+		return err
+	}
+	reveal, _ := cmd.Flags().GetBool("reveal")
+	settings := []confSetting{}
+	for _, e := range doc.Entries() {
+		s := confSetting{Name: e.Name, Line: e.Line, Value: e.Value}
+		if workbench.IsEncrypted(e.Value) {
+			s.Encrypted = true
+			s.Checksum, _ = workbench.Checksum(e.Value)
+			if plaintext, err := key.Decrypt(e.Value); err == nil {
+				s.Value, s.Decrypted = plaintext, true
+			} else {
+				s.Value = ""
+			}
+		}
+		if !reveal && (s.Encrypted || sensitiveName.MatchString(s.Name)) {
+			s.Value = mask(s.Value)
+		}
+		settings = append(settings, s)
+	}
+	switch format := cmd.Flag("format").Value.String(); format {
+	case "json":
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(settings)
+	case "text":
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tNAME\tVALUE\tENCRYPTED")
+	for _, s := range settings {
+		encrypted := "no"
+		switch {
+		case s.Decrypted:
+			encrypted = "yes (" + s.Checksum + ")"
+		case s.Encrypted:
+			encrypted = "cannot decrypt (" + s.Checksum + ")"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Line, s.Name, s.Value, encrypted)
+	}
+	return w.Flush()
+}
+
+func runWorkbenchConfVerify(cmd *cobra.Command, args []string) error {
+	key, err := workbenchKey(cmd)
+	if err != nil {
+		return err
+	}
+	doc, err := conffile.ReadFile(args[0], conffile.Workbench)
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+	checked, mismatched := 0, 0
+	for _, e := range doc.Entries() {
+		if !workbench.IsEncrypted(e.Value) {
+			continue
+		}
+		sum, _ := workbench.Checksum(e.Value)
+		checked++
+		if sum != key.Fingerprint() {
+			mismatched++
+			fmt.Fprintf(w, "MISMATCH  %s (line %d): encrypted with key %s, but the key is %s\n",
+				e.Name, e.Line, sum, key.Fingerprint())
+			continue
+		}
+		fmt.Fprintf(w, "OK        %s (line %d)\n", e.Name, e.Line)
+	}
+	if checked == 0 {
+		_, err = fmt.Fprintln(w, "No encrypted settings found")
+		return err
+	}
+	if mismatched > 0 {
+		return fmt.Errorf("%d of %d encrypted settings do not match key %s",
+			mismatched, checked, key.Fingerprint())
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(workbenchCmd)
+	workbenchCmd.AddCommand(workbenchCheckKeyCmd)
+	workbenchCmd.AddCommand(workbenchConfCmd)
+	workbenchConfCmd.AddCommand(workbenchConfSetCmd)
+	workbenchConfCmd.AddCommand(workbenchConfShowCmd)
+	workbenchConfCmd.AddCommand(workbenchConfVerifyCmd)
+	addFormatFlag(workbenchCheckKeyCmd, "text", "json")
+	addFormatFlag(workbenchConfShowCmd, "text", "json")
+	workbenchConfShowCmd.Flags().Bool("reveal", false,
+		"Show values in full instead of masking them")
+}
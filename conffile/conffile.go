@@ -0,0 +1,262 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package conffile reads and edits the configuration files used by Posit
+// products, and .env files, replacing individual values while preserving
+// comments and layout.
+package conffile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies a configuration file syntax.
+type Format string
+
+const (
+	// GCFG is the INI-like format used by Connect and Package Manager,
+	// e.g. rstudio-connect.gcfg.
+	GCFG Format = "gcfg"
+	// Workbench is the key=value format used by Workbench, e.g.
+	// database.conf.
+	Workbench Format = "workbench"
+	// Dotenv is the NAME=value format of .env files.
+	Dotenv Format = "dotenv"
+)
+
+var (
+	// ErrUnknownFormat reports a format that is not supported.
+	ErrUnknownFormat = errors.New("unknown configuration file format")
+	// ErrInvalidName reports a setting name that is not valid for a format.
+	ErrInvalidName = errors.New("invalid setting name")
+	// ErrNoEntry reports an entry index that is out of range.
+	ErrNoEntry = errors.New("no such setting")
+)
+
+// Formats lists the supported formats.
+func Formats() []Format {
+	return []Format{GCFG, Workbench, Dotenv}
+}
+
+// ParseFormat returns the Format with the given name.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats() {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownFormat, s)
+}
+
+// DetectFormat guesses the format of a file from its name.
+func DetectFormat(path string) (Format, error) {
+	if base := strings.ToLower(filepath.Base(path)); base == ".env" ||
+		strings.HasPrefix(base, ".env.") {
+		return Dotenv, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".env":
+		return Dotenv, nil
+	case ".gcfg":
+		return GCFG, nil
+	case ".conf":
+		return Workbench, nil
+	}
+	return "", fmt.Errorf("%w for %s", ErrUnknownFormat, path)
+}
+
+// Entry is a single setting in a Document.
+type Entry struct {
+	// Name identifies the setting, in the form accepted by Get and Set.
+	Name string
+	// Value is the setting's value, unquoted.
+	Value string
+	// Line is the 1-based line number of the setting.
+	Line int
+}
+
+// Document is a parsed configuration file.
+type Document interface {
+	// Format returns the document's format.
+	Format() Format
+	// Entries lists every setting, in order.
+	Entries() []Entry
+	// Get returns the value of a setting. If it is set more than once, the
+	// last value is returned.
+	Get(name string) (string, bool)
+	// Set changes the value of a setting, adding it if necessary. If it is
+	// set more than once, the last value is changed.
+	Set(name, value string) error
+	// SetEntry changes the value of the i-th setting listed by Entries,
+	// which need not be the last with its name.
+	SetEntry(i int, value string) error
+	// Bytes returns the document's contents, including any changes.
+	Bytes() []byte
+}
+
+// Parse parses a document in the given format.
+func Parse(format Format, data []byte) (Document, error) {
+	switch format {
+	case GCFG:
+		return parseGCFG(data)
+	case Workbench:
+		return parseConf(data), nil
+	case Dotenv:
+		return parseDotenv(data)
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// ReadFile reads and parses a document. If format is empty, it is detected
+// from the file name.
+func ReadFile(path string, format Format) (Document, error) {
+	if format == "" {
+		var err error
+		if format, err = DetectFormat(path); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// WriteFile atomically replaces the contents of a file with one that has the
+// given permissions, so that readers never see partial contents. If path is a
+// symbolic link, the file it points to is replaced instead, and the owner of an
+// existing file is kept. Use ExistingPerm() to keep its permissions as well.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		if err := chownLike(f, info); err != nil {
+			f.Close()
+			return fmt.Errorf("cannot keep the owner of %s: %w", path, err)
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// ExistingPerm returns the permissions of the file at path, or perm if it does
+// not exist.
+func ExistingPerm(path string, perm os.FileMode) os.FileMode {
+	if info, err := os.Stat(path); err == nil {
+		return info.Mode().Perm()
+	}
+	return perm
+}
+
+// line is a line of a document, split around its value, if it has one.
+type line struct {
+	// The line's text, including its line ending.
+	text string
+	// The byte range of the value in text, or -1 if there is none.
+	start, end int
+	// The setting name.
+	name string
+	// The unquoted value.
+	value string
+}
+
+func (l *line) hasValue() bool {
+	return l.start >= 0
+}
+
+// entryLine returns the index of the i-th of n lines for which hasValue
+// returns true.
+func entryLine(n, i int, hasValue func(int) bool) (int, error) {
+	seen := 0
+	for j := range n {
+		if !hasValue(j) {
+			continue
+		}
+		if seen == i {
+			return j, nil
+		}
+		seen++
+	}
+	return -1, fmt.Errorf("%w %d", ErrNoEntry, i)
+}
+
+func (l *line) replace(raw, value string) {
+	l.text = l.text[:l.start] + raw + l.text[l.end:]
+	l.end = l.start + len(raw)
+	l.value = value
+}
+
+// splitLines splits data into lines that keep their line endings.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineEnding returns the line ending used by the document.
+func lineEnding(lines []*line) string {
+	for _, l := range lines {
+		if strings.HasSuffix(l.text, "\r\n") {
+			return "\r\n"
+		}
+	}
+	return "\n"
+}
+
+// joinLines joins lines back together.
+func joinLines(lines []*line) []byte {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(l.text)
+	}
+	return []byte(b.String())
+}
+
+// insertLine adds a line after index i, ensuring that the previous line ends
+// with a line ending.
+func insertLine(lines []*line, i int, l *line) []*line {
+	eol := lineEnding(lines)
+	if i >= 0 && !strings.HasSuffix(lines[i].text, "\n") {
+		lines[i].text += eol
+	}
+	if !strings.HasSuffix(l.text, "\n") {
+		l.text += eol
+	}
+	lines = append(lines, nil)
+	copy(lines[i+2:], lines[i+1:])
+	lines[i+1] = l
+	return lines
+}
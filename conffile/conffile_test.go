@@ -0,0 +1,246 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package conffile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+type ConffileSuite struct{}
+
+const gcfgExample = `; Posit Connect configuration
+[Server]
+Address = https://connect.example.com
+
+[Database]
+Provider = postgres ; inline comment
+
+[Postgres]
+URL = "postgres://connect@db/connect"
+Password = old-password # the old one
+
+[OAuth2 "github"]
+ClientSecret = abc
+`
+
+func (s *ConffileSuite) TestGCFG(c *check.C) {
+	doc, err := Parse(GCFG, []byte(gcfgExample))
+	c.Assert(err, check.IsNil)
+	c.Check(doc.Entries(), check.DeepEquals, []Entry{
+		{"Server.Address", "https://connect.example.com", 3},
+		{"Database.Provider", "postgres", 6},
+		{"Postgres.URL", "postgres://connect@db/connect", 9},
+		{"Postgres.Password", "old-password", 10},
+		{"OAuth2.github.ClientSecret", "abc", 13},
+	})
+
+	// Section and variable names are case-insensitive.
+	value, ok := doc.Get("postgres.password")
+	c.Check(ok, check.Equals, true)
+	c.Check(value, check.Equals, "old-password")
+	_, ok = doc.Get("OAuth2.GitHub.ClientSecret")
+	c.Check(ok, check.Equals, false)
+
+	c.Assert(doc.Set("Postgres.Password", "new; password"), check.IsNil)
+	c.Assert(doc.Set("Postgres.URL", "postgres://db/rsc"), check.IsNil)
+	c.Assert(doc.Set("Database.Dir", "/var/lib"), check.IsNil)
+	c.Assert(doc.Set("OAuth2.google.ClientSecret", "xyz"), check.IsNil)
+	c.Check(string(doc.Bytes()), check.Equals, `; Posit Connect configuration
+[Server]
+Address = https://connect.example.com
+
+[Database]
+Provider = postgres ; inline comment
+Dir = /var/lib
+
+[Postgres]
+URL = postgres://db/rsc
+Password = "new; password" # the old one
+
+[OAuth2 "github"]
+ClientSecret = abc
+
+[OAuth2 "google"]
+ClientSecret = xyz
+`)
+
+	// Changes survive a round trip.
+	doc, err = Parse(GCFG, doc.Bytes())
+	c.Assert(err, check.IsNil)
+	value, _ = doc.Get("Postgres.Password")
+	c.Check(value, check.Equals, "new; password")
+
+	c.Check(doc.Set("Password", "x"), check.ErrorMatches, `invalid setting name "Password".*`)
+}
+
+func (s *ConffileSuite) TestGCFGValues(c *check.C) {
+	for raw, want := range map[string]string{
+		`plain`:                  "plain",
+		`two words  ; comment`:   "two words",
+		`"quoted ; value"`:       "quoted ; value",
+		`"a \"b\" \\ c\td"`:      "a \"b\" \\ c\td",
+		`mixed "  spaces  " end`: "mixed   spaces   end",
+	} {
+		doc, err := Parse(GCFG, []byte("[S]\nName = "+raw+"\n"))
+		c.Assert(err, check.IsNil, check.Commentf("%s", raw))
+		value, _ := doc.Get("S.Name")
+		c.Check(value, check.Equals, want, check.Commentf("%s", raw))
+
+		// Set quotes values only when necessary.
+		c.Assert(doc.Set("S.Name", want), check.IsNil)
+		doc, err = Parse(GCFG, doc.Bytes())
+		c.Assert(err, check.IsNil)
+		value, _ = doc.Get("S.Name")
+		c.Check(value, check.Equals, want)
+	}
+
+	for _, bad := range []string{
+		"Name = value\n",
+		"[S]\nName = \"unterminated\n",
+		"[S]\nName = bad \\q escape\n",
+		"[S \"sub]\n",
+	} {
+		_, err := Parse(GCFG, []byte(bad))
+		c.Check(err, check.NotNil, check.Commentf("%q", bad))
+	}
+}
+
+func (s *ConffileSuite) TestWorkbench(c *check.C) {
+	doc, err := Parse(Workbench, []byte("# Database\nprovider=postgresql\r\npassword = secret \r\n"))
+	c.Assert(err, check.IsNil)
+	c.Check(doc.Entries(), check.DeepEquals, []Entry{
+		{"provider", "postgresql", 2},
+		{"password", "secret", 3},
+	})
+	c.Assert(doc.Set("password", "other"), check.IsNil)
+	c.Assert(doc.Set("host", "db"), check.IsNil)
+	c.Check(string(doc.Bytes()), check.Equals,
+		"# Database\nprovider=postgresql\r\npassword = other \r\nhost=db\r\n")
+
+	c.Check(doc.Set("bad=name", "x"), check.ErrorMatches, `invalid setting name.*`)
+	c.Check(doc.Set("host", "a\nb"), check.ErrorMatches, `value for host cannot.*`)
+
+	// Empty documents are fine.
+	doc, err = Parse(Workbench, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(doc.Set("host", "db"), check.IsNil)
+	c.Check(string(doc.Bytes()), check.Equals, "host=db\n")
+}
+
+func (s *ConffileSuite) TestDotenv(c *check.C) {
+	doc, err := Parse(Dotenv, []byte(`# Secrets
+export DB_PASSWORD="hunter2" # inline
+API_TOKEN='tok\en'
+PLAIN = value # comment
+PEM="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+ESCAPED="a\"b\nc"
+`))
+	c.Assert(err, check.IsNil)
+	c.Check(doc.Entries(), check.DeepEquals, []Entry{
+		{"DB_PASSWORD", "hunter2", 2},
+		{"API_TOKEN", `tok\en`, 3},
+		{"PLAIN", "value", 4},
+		{"PEM", "-----BEGIN KEY-----\nabc\n-----END KEY-----", 5},
+		{"ESCAPED", "a\"b\nc", 8},
+	})
+
+	// Quoting is kept.
+	c.Assert(doc.Set("DB_PASSWORD", "abc="), check.IsNil)
+	c.Assert(doc.Set("API_TOKEN", "x/y"), check.IsNil)
+	c.Assert(doc.Set("PLAIN", "two words"), check.IsNil)
+	c.Assert(doc.Set("PEM", "short"), check.IsNil)
+	c.Assert(doc.Set("NEW", "new"), check.IsNil)
+	c.Check(string(doc.Bytes()), check.Equals, `# Secrets
+export DB_PASSWORD="abc=" # inline
+API_TOKEN='x/y'
+PLAIN = "two words" # comment
+PEM="short"
+ESCAPED="a\"b\nc"
+NEW=new
+`)
+	c.Check(doc.Set("1BAD", "x"), check.ErrorMatches, `invalid setting name "1BAD"`)
+
+	for _, bad := range []string{"NAME\n", "A=\"unterminated\n", "BAD NAME=x\n"} {
+		_, err := Parse(Dotenv, []byte(bad))
+		c.Check(err, check.NotNil, check.Commentf("%q", bad))
+	}
+	f, _ := DetectFormat("/srv/app/.env.production")
+	c.Check(f, check.Equals, Dotenv)
+}
+
+func (s *ConffileSuite) TestSetEntry(c *check.C) {
+	for format, data := range map[Format]string{
+		GCFG:      "[Auth]\nUser = a\nUser = b\nFlag\n",
+		Workbench: "user=a\nuser=b\nflag=\n",
+		Dotenv:    "USER=a\nUSER='b'\nFLAG=\n",
+	} {
+		doc, err := Parse(format, []byte(data))
+		c.Assert(err, check.IsNil)
+		// Repeated settings are changed individually.
+		c.Assert(doc.SetEntry(0, "first"), check.IsNil)
+		c.Assert(doc.SetEntry(2, "set"), check.IsNil)
+		var values []string
+		for _, e := range doc.Entries() {
+			values = append(values, e.Value)
+		}
+		c.Check(values, check.DeepEquals, []string{"first", "b", "set"}, check.Commentf("%s", format))
+		c.Check(doc.SetEntry(3, "x"), check.ErrorMatches, `no such setting 3`)
+	}
+	doc, _ := Parse(Workbench, []byte("user=a\n"))
+	c.Check(doc.SetEntry(0, "a\nb"), check.ErrorMatches, `value for user cannot.*`)
+}
+
+func (s *ConffileSuite) TestFiles(c *check.C) {
+	f, err := DetectFormat("/etc/rstudio-connect/rstudio-connect.gcfg")
+	c.Check(err, check.IsNil)
+	c.Check(f, check.Equals, GCFG)
+	f, err = DetectFormat("/etc/rstudio/database.conf")
+	c.Check(err, check.IsNil)
+	c.Check(f, check.Equals, Workbench)
+	_, err = DetectFormat("config.yaml")
+	c.Check(err, check.ErrorMatches, `unknown configuration file format for config.yaml`)
+	_, err = ParseFormat("ini")
+	c.Check(err, check.ErrorMatches, `unknown configuration file format "ini"`)
+
+	path := filepath.Join(c.MkDir(), "database.conf")
+	c.Assert(WriteFile(path, []byte("password=a\n"), 0600), check.IsNil)
+	c.Assert(os.Chmod(path, 0640), check.IsNil)
+	doc, err := ReadFile(path, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(doc.Set("password", "b"), check.IsNil)
+	c.Assert(WriteFile(path, doc.Bytes(), ExistingPerm(path, 0600)), check.IsNil)
+
+	// The existing permissions are kept.
+	info, err := os.Stat(path)
+	c.Assert(err, check.IsNil)
+	c.Check(info.Mode().Perm(), check.Equals, os.FileMode(0640))
+	data, _ := os.ReadFile(path)
+	c.Check(string(data), check.Equals, "password=b\n")
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	c.Check(entries, check.HasLen, 1)
+
+	// Symbolic links are kept, and the file they point to is replaced.
+	link := filepath.Join(c.MkDir(), "link.conf")
+	c.Assert(os.Symlink(path, link), check.IsNil)
+	c.Assert(WriteFile(link, []byte("password=c\n"), ExistingPerm(link, 0600)), check.IsNil)
+	target, err := os.Readlink(link)
+	c.Assert(err, check.IsNil)
+	c.Check(target, check.Equals, path)
+	data, _ = os.ReadFile(path)
+	c.Check(string(data), check.Equals, "password=c\n")
+	info, err = os.Stat(path)
+	c.Assert(err, check.IsNil)
+	c.Check(info.Mode().Perm(), check.Equals, os.FileMode(0640))
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&ConffileSuite{})
+	check.TestingT(t)
+}
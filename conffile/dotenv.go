@@ -0,0 +1,194 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package conffile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// dotenvDocument is a .env file, made of NAME=value lines, optionally prefixed
+// with "export". Values may be unquoted, 'single-quoted' (literal), or
+// "double-quoted" (with escapes), and quoted values may span several lines.
+// Comments start with "#".
+type dotenvDocument struct {
+	lines []*line
+	// The quote character used by each value, if any.
+	quotes map[*line]byte
+}
+
+var dotenvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+func parseDotenv(data []byte) (Document, error) {
+	doc := &dotenvDocument{quotes: map[*line]byte{}}
+	texts := splitLines(data)
+	for i := 0; i < len(texts); i++ {
+		lineno := i + 1
+		l := &line{text: texts[i], start: -1, end: -1}
+		doc.lines = append(doc.lines, l)
+		content := strings.TrimRight(l.text, "\r\n")
+		trimmed := strings.TrimSpace(content)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		eq := strings.Index(content, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected NAME=value", lineno)
+		}
+		name := strings.TrimSpace(content[:eq])
+		if after, ok := strings.CutPrefix(name, "export"); ok && after != "" &&
+			(after[0] == ' ' || after[0] == '\t') {
+			name = strings.TrimSpace(after)
+		}
+		if !dotenvName.MatchString(name) {
+			return nil, fmt.Errorf("line %d: invalid variable name", lineno)
+		}
+		l.name = name
+		l.start = eq + 1
+		for l.start < len(content) && (content[l.start] == ' ' || content[l.start] == '\t') {
+			l.start++
+		}
+		if l.start == len(content) || (content[l.start] != '"' && content[l.start] != '\'') {
+			// Unquoted values end at a comment.
+			value := content[l.start:]
+			if c := strings.Index(value, " #"); c >= 0 {
+				value = value[:c]
+			} else if c := strings.Index(value, "\t#"); c >= 0 {
+				value = value[:c]
+			}
+			l.value = strings.TrimRight(value, " \t")
+			l.end = l.start + len(l.value)
+			continue
+		}
+		// Quoted values may continue onto the following lines.
+		quote := content[l.start]
+		for {
+			value, end, ok := unquoteDotenv(l.text[l.start:], quote)
+			if ok {
+				l.value, l.end = value, l.start+end
+				break
+			}
+			if i+1 >= len(texts) {
+				return nil, fmt.Errorf("line %d: unterminated quote", lineno)
+			}
+			i++
+			l.text += texts[i]
+		}
+		doc.quotes[l] = quote
+	}
+	return doc, nil
+}
+
+// unquoteDotenv reads a quoted value from the start of s, returning the value
+// and the length of its source, or false if it is not terminated.
+func unquoteDotenv(s string, quote byte) (string, int, bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, true
+		case c == '\\' && quote == '"' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, false
+}
+
+// quoteDotenv returns the source for a value, using the given quote character
+// where possible.
+func quoteDotenv(value string, quote byte) string {
+	if quote == 0 && value != "" && !strings.ContainsAny(value, " \t\r\n#'\"\\$") {
+		return value
+	}
+	if quote == '\'' && !strings.ContainsAny(value, "'\r\n") {
+		return "'" + value + "'"
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// Format implements Document.
+func (d *dotenvDocument) Format() Format {
+	return Dotenv
+}
+
+// Entries implements Document.
+func (d *dotenvDocument) Entries() []Entry {
+	entries := []Entry{}
+	lineno := 1
+	for _, l := range d.lines {
+		if l.hasValue() {
+			entries = append(entries, Entry{l.name, l.value, lineno})
+		}
+		lineno += max(1, strings.Count(strings.TrimSuffix(l.text, "\n"), "\n")+1)
+	}
+	return entries
+}
+
+func (d *dotenvDocument) find(name string) *line {
+	for i := len(d.lines) - 1; i >= 0; i-- {
+		if l := d.lines[i]; l.hasValue() && l.name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Get implements Document.
+func (d *dotenvDocument) Get(name string) (string, bool) {
+	if l := d.find(name); l != nil {
+		return l.value, true
+	}
+	return "", false
+}
+
+// Set implements Document. Values keep their original quoting where possible.
+func (d *dotenvDocument) Set(name, value string) error {
+	if !dotenvName.MatchString(name) {
+		return fmt.Errorf("%w %q", ErrInvalidName, name)
+	}
+	if l := d.find(name); l != nil {
+		l.replace(quoteDotenv(value, d.quotes[l]), value)
+		return nil
+	}
+	raw := quoteDotenv(value, 0)
+	text := name + "=" + raw
+	d.lines = insertLine(d.lines, len(d.lines)-1, &line{
+		text: text, start: len(name) + 1, end: len(text), name: name, value: value,
+	})
+	return nil
+}
+
+// SetEntry implements Document.
+func (d *dotenvDocument) SetEntry(i int, value string) error {
+	j, err := entryLine(len(d.lines), i, func(j int) bool { return d.lines[j].hasValue() })
+	if err != nil {
+		return err
+	}
+	l := d.lines[j]
+	l.replace(quoteDotenv(value, d.quotes[l]), value)
+	return nil
+}
+
+// Bytes implements Document.
+func (d *dotenvDocument) Bytes() []byte {
+	return joinLines(d.lines)
+}
@@ -0,0 +1,338 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package conffile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gcfgDocument is a gcfg file, as used by Connect and Package Manager. It is
+// made of [Section] or [Section "subsection"] headers followed by Name = value
+// lines. Section and variable names are case-insensitive; subsection names are
+// not. Comments start with ";" or "#".
+//
+// Settings are named Section.Name, or Section.subsection.Name.
+type gcfgDocument struct {
+	lines []*gcfgLine
+}
+
+type gcfgLine struct {
+	line
+	// The section and subsection that the line belongs to.
+	section, subsection string
+	// Whether this is a section header.
+	header bool
+}
+
+func parseGCFG(data []byte) (Document, error) {
+	doc := &gcfgDocument{}
+	var section, subsection string
+	for i, text := range splitLines(data) {
+		l := &gcfgLine{line: line{text: text, start: -1, end: -1}}
+		doc.lines = append(doc.lines, l)
+		content := strings.TrimRight(text, "\r\n")
+		trimmed := strings.TrimSpace(content)
+		switch {
+		case trimmed == "" || trimmed[0] == ';' || trimmed[0] == '#':
+		case trimmed[0] == '[':
+			var err error
+			section, subsection, err = parseHeader(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			l.header = true
+		default:
+			if section == "" {
+				return nil, fmt.Errorf("line %d: variable outside of a section", i+1)
+			}
+			if err := parseVariable(&l.line, content); err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+		}
+		l.section, l.subsection = section, subsection
+	}
+	return doc, nil
+}
+
+// parseHeader parses a [Section] or [Section "subsection"] header.
+func parseHeader(s string) (string, string, error) {
+	end := strings.LastIndex(s, "]")
+	if end < 0 {
+		return "", "", fmt.Errorf("invalid section header %q", s)
+	}
+	if rest := strings.TrimSpace(s[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+		return "", "", fmt.Errorf("invalid section header %q", s)
+	}
+	inner := strings.TrimSpace(s[1:end])
+	name, sub, hasSub := strings.Cut(inner, " ")
+	if name == "" {
+		return "", "", fmt.Errorf("invalid section header %q", s)
+	}
+	if !hasSub {
+		return name, "", nil
+	}
+	sub, err := strconv.Unquote(strings.TrimSpace(sub))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid subsection in %q", s)
+	}
+	return name, sub, nil
+}
+
+// parseVariable parses a Name = value line into l.
+func parseVariable(l *line, content string) error {
+	eq := strings.Index(content, "=")
+	if eq < 0 {
+		// A name on its own is a boolean.
+		l.name = strings.TrimSpace(stripComment(content))
+		l.start, l.end = len(content), len(content)
+		l.value = "true"
+		return nil
+	}
+	l.name = strings.TrimSpace(content[:eq])
+	if l.name == "" {
+		return fmt.Errorf("missing variable name")
+	}
+	l.start = eq + 1
+	for l.start < len(content) && (content[l.start] == ' ' || content[l.start] == '\t') {
+		l.start++
+	}
+	value, end, err := unquoteValue(content[l.start:])
+	if err != nil {
+		return err
+	}
+	l.end = l.start + end
+	l.value = value
+	return nil
+}
+
+// stripComment removes a trailing comment from a line without quotes.
+func stripComment(s string) string {
+	if i := strings.IndexAny(s, ";#"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// unquoteValue parses a raw value, returning its unquoted form and the length
+// of the raw value, excluding any comment and trailing whitespace.
+func unquoteValue(raw string) (string, int, error) {
+	var b strings.Builder
+	quoted := false
+	end := 0
+	// Whitespace is only kept if something follows it.
+	pending := ""
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(raw) {
+				return "", 0, fmt.Errorf("invalid escape at end of value")
+			}
+			i++
+			b.WriteString(pending)
+			pending = ""
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case '\\', '"':
+				b.WriteByte(raw[i])
+			default:
+				return "", 0, fmt.Errorf("invalid escape \\%c", raw[i])
+			}
+			end = i + 1
+		case c == '"':
+			b.WriteString(pending)
+			pending = ""
+			quoted = !quoted
+			end = i + 1
+		case quoted:
+			b.WriteByte(c)
+			end = i + 1
+		case c == ';' || c == '#':
+			return b.String(), end, nil
+		case c == ' ' || c == '\t':
+			pending += string(c)
+		default:
+			b.WriteString(pending)
+			pending = ""
+			b.WriteByte(c)
+			end = i + 1
+		}
+	}
+	if quoted {
+		return "", 0, fmt.Errorf("unterminated quote")
+	}
+	return b.String(), end, nil
+}
+
+// quoteValue returns the raw form of a value, quoting it only if necessary.
+func quoteValue(value string) string {
+	if value != "" && strings.TrimSpace(value) == value &&
+		!strings.ContainsAny(value, "\"\\;#\n\t\b") {
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\b", `\b`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// splitName splits a Section.Name or Section.subsection.Name setting name.
+func splitName(name string) (section, subsection, variable string, err error) {
+	first := strings.Index(name, ".")
+	last := strings.LastIndex(name, ".")
+	if first <= 0 || last == len(name)-1 {
+		return "", "", "", fmt.Errorf("%w %q: expected Section.Name or Section.subsection.Name",
+			ErrInvalidName, name)
+	}
+	section, variable = name[:first], name[last+1:]
+	if first != last {
+		subsection = name[first+1 : last]
+	}
+	if strings.ContainsAny(section, " \t[]\"") || strings.ContainsAny(variable, " \t=;#") {
+		return "", "", "", fmt.Errorf("%w %q", ErrInvalidName, name)
+	}
+	return section, subsection, variable, nil
+}
+
+func (l *gcfgLine) entryName() string {
+	if l.subsection != "" {
+		return l.section + "." + l.subsection + "." + l.name
+	}
+	return l.section + "." + l.name
+}
+
+func (l *gcfgLine) inSection(section, subsection string) bool {
+	return strings.EqualFold(l.section, section) && l.subsection == subsection
+}
+
+// Format implements Document.
+func (d *gcfgDocument) Format() Format {
+	return GCFG
+}
+
+// Entries implements Document.
+func (d *gcfgDocument) Entries() []Entry {
+	entries := []Entry{}
+	for i, l := range d.lines {
+		if l.hasValue() {
+			entries = append(entries, Entry{l.entryName(), l.value, i + 1})
+		}
+	}
+	return entries
+}
+
+func (d *gcfgDocument) find(section, subsection, name string) *gcfgLine {
+	for i := len(d.lines) - 1; i >= 0; i-- {
+		l := d.lines[i]
+		if l.hasValue() && l.inSection(section, subsection) && strings.EqualFold(l.name, name) {
+			return l
+		}
+	}
+	return nil
+}
+
+// Get implements Document.
+func (d *gcfgDocument) Get(name string) (string, bool) {
+	section, subsection, variable, err := splitName(name)
+	if err != nil {
+		return "", false
+	}
+	if l := d.find(section, subsection, variable); l != nil {
+		return l.value, true
+	}
+	return "", false
+}
+
+// Set implements Document.
+func (d *gcfgDocument) Set(name, value string) error {
+	section, subsection, variable, err := splitName(name)
+	if err != nil {
+		return err
+	}
+	raw := quoteValue(value)
+	if l := d.find(section, subsection, variable); l != nil {
+		l.set(raw, value)
+		return nil
+	}
+	// Add the variable after the last line of its section, ignoring
+	// trailing blank lines and comments.
+	after := -1
+	for i, l := range d.lines {
+		if l.inSection(section, subsection) && (l.header || l.hasValue()) {
+			after = i
+		}
+	}
+	prefix := variable + " = "
+	added := &gcfgLine{
+		line: line{
+			text: prefix + raw, start: len(prefix), end: len(prefix) + len(raw),
+			name: variable, value: value,
+		},
+		section: section, subsection: subsection,
+	}
+	if after < 0 {
+		header := "[" + section + "]"
+		if subsection != "" {
+			header = "[" + section + " " + strconv.Quote(subsection) + "]"
+		}
+		after = len(d.lines) - 1
+		if len(d.lines) > 0 {
+			d.insert(after, &gcfgLine{line: line{text: "", start: -1, end: -1}})
+			after++
+		}
+		d.insert(after, &gcfgLine{
+			line:    line{text: header, start: -1, end: -1},
+			section: section, subsection: subsection, header: true,
+		})
+		after++
+	}
+	d.insert(after, added)
+	return nil
+}
+
+// SetEntry implements Document.
+func (d *gcfgDocument) SetEntry(i int, value string) error {
+	j, err := entryLine(len(d.lines), i, func(j int) bool { return d.lines[j].hasValue() })
+	if err != nil {
+		return err
+	}
+	l := d.lines[j]
+	l.set(quoteValue(value), value)
+	return nil
+}
+
+func (l *gcfgLine) set(raw, value string) {
+	if l.start == l.end && !strings.Contains(l.text, "=") {
+		// A boolean with no value.
+		l.text = strings.Replace(l.text, l.name, l.name+" = ", 1)
+		l.start = strings.Index(l.text, "=") + 2
+		l.end = l.start
+	}
+	l.replace(raw, value)
+}
+
+func (d *gcfgDocument) insert(after int, l *gcfgLine) {
+	plain := make([]*line, len(d.lines))
+	for i, gl := range d.lines {
+		plain[i] = &gl.line
+	}
+	insertLine(plain, after, &l.line)
+	d.lines = append(d.lines, nil)
+	copy(d.lines[after+2:], d.lines[after+1:])
+	d.lines[after+1] = l
+}
+
+// Bytes implements Document.
+func (d *gcfgDocument) Bytes() []byte {
+	var b strings.Builder
+	for _, l := range d.lines {
+		b.WriteString(l.text)
+	}
+	return []byte(b.String())
+}
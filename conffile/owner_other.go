@@ -0,0 +1,13 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !unix
+
+package conffile
+
+import "os"
+
+// chownLike does nothing on systems without Unix file ownership.
+func chownLike(f *os.File, info os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package conffile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// confDocument is a Workbench configuration file, made of name=value lines
+// and lines starting with "#" as comments. Names are case-sensitive, and values
+// are not quoted.
+type confDocument struct {
+	lines []*line
+}
+
+func parseConf(data []byte) *confDocument {
+	doc := &confDocument{}
+	for _, text := range splitLines(data) {
+		l := &line{text: text, start: -1, end: -1}
+		doc.lines = append(doc.lines, l)
+		content := strings.TrimRight(text, "\r\n")
+		trimmed := strings.TrimSpace(content)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		eq := strings.Index(content, "=")
+		if eq < 0 {
+			continue
+		}
+		l.name = strings.TrimSpace(content[:eq])
+		// The value excludes surrounding whitespace.
+		l.start = eq + 1
+		for l.start < len(content) && (content[l.start] == ' ' || content[l.start] == '\t') {
+			l.start++
+		}
+		l.end = len(strings.TrimRight(content, " \t"))
+		if l.end < l.start {
+			l.end = l.start
+		}
+		l.value = content[l.start:l.end]
+	}
+	return doc
+}
+
+// Format implements Document.
+func (d *confDocument) Format() Format {
+	return Workbench
+}
+
+// Entries implements Document.
+func (d *confDocument) Entries() []Entry {
+	entries := []Entry{}
+	for i, l := range d.lines {
+		if l.hasValue() {
+			entries = append(entries, Entry{l.name, l.value, i + 1})
+		}
+	}
+	return entries
+}
+
+func (d *confDocument) find(name string) *line {
+	for i := len(d.lines) - 1; i >= 0; i-- {
+		if l := d.lines[i]; l.hasValue() && l.name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Get implements Document.
+func (d *confDocument) Get(name string) (string, bool) {
+	if l := d.find(name); l != nil {
+		return l.value, true
+	}
+	return "", false
+}
+
+// Set implements Document.
+func (d *confDocument) Set(name, value string) error {
+	if name == "" || strings.ContainsAny(name, "=#\r\n") || strings.TrimSpace(name) != name {
+		return fmt.Errorf("%w %q", ErrInvalidName, name)
+	}
+	if err := checkConfValue(name, value); err != nil {
+		return err
+	}
+	if l := d.find(name); l != nil {
+		l.replace(value, value)
+		return nil
+	}
+	text := name + "=" + value
+	d.lines = insertLine(d.lines, len(d.lines)-1, &line{
+		text: text, start: len(name) + 1, end: len(text), name: name, value: value,
+	})
+	return nil
+}
+
+// SetEntry implements Document.
+func (d *confDocument) SetEntry(i int, value string) error {
+	j, err := entryLine(len(d.lines), i, func(j int) bool { return d.lines[j].hasValue() })
+	if err != nil {
+		return err
+	}
+	l := d.lines[j]
+	if err := checkConfValue(l.name, value); err != nil {
+		return err
+	}
+	l.replace(value, value)
+	return nil
+}
+
+func checkConfValue(name, value string) error {
+	if strings.ContainsAny(value, "\r\n") || strings.TrimSpace(value) != value {
+		return fmt.Errorf("value for %s cannot contain line breaks or surrounding spaces", name)
+	}
+	return nil
+}
+
+// Bytes implements Document.
+func (d *confDocument) Bytes() []byte {
+	return joinLines(d.lines)
+}
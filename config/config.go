@@ -0,0 +1,261 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package config loads rskey configuration files, which hold named profiles
+// of default flag values.
+//
+// A configuration file looks like this:
+//
+//	default_profile = "ppm"
+//
+//	[profiles.ppm]
+//	keyfile = "/var/lib/rstudio-pm/rstudio-pm.key"
+//	mode = "fips"
+//	fips_policy = "enforce"
+//
+//	[profiles.workbench]
+//	product = "workbench"
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+)
+
+// SystemPath is the location of the system-wide configuration file.
+const SystemPath = "/etc/rskey/config.toml"
+
+// SystemPolicyPath is the location of the system-wide crypto policy. See
+// crypt.Policy.
+const SystemPolicyPath = "/etc/rskey/policy.json"
+
+// EnvPrefix is the prefix of environment variables that override flags, for
+// example RSKEY_MODE for --mode.
+const EnvPrefix = "RSKEY_"
+
+// ErrUnknownProfile reports a profile that is not in any configuration file.
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// Profile holds default values for a set of flags.
+type Profile struct {
+	// Keyfile is the path to a key file.
+	Keyfile string `toml:"keyfile"`
+	// Key is the label of a key in the keystore.
+	Key string `toml:"key"`
+	// Keystore is the keystore directory.
+	Keystore string `toml:"keystore"`
+	// Mode is the encryption mode.
+	Mode string `toml:"mode"`
+	// Product is the name of a Posit product.
+	Product string `toml:"product"`
+	// ProductVersion is the version of the product.
+	ProductVersion string `toml:"product_version"`
+	// Format is the output format.
+	Format string `toml:"format"`
+	// FIPSPolicy is the FIPS policy.
+	FIPSPolicy string `toml:"fips_policy"`
+	// Policy is the path to a crypto policy file.
+	Policy string `toml:"policy"`
+}
+
+// Flags returns the values set in the profile, keyed by flag name.
+func (p Profile) Flags() map[string]string {
+	flags := map[string]string{}
+	for name, value := range map[string]string{
+		"keyfile":         p.Keyfile,
+		"key":             p.Key,
+		"keystore":        p.Keystore,
+		"mode":            p.Mode,
+		"product":         p.Product,
+		"product-version": p.ProductVersion,
+		"format":          p.Format,
+		"fips-policy":     p.FIPSPolicy,
+		"policy":          p.Policy,
+	} {
+		if value != "" {
+			flags[name] = value
+		}
+	}
+	return flags
+}
+
+// merge sets any fields of p that are set in other.
+func (p *Profile) merge(other Profile) {
+	for _, f := range []struct{ dst, src *string }{
+		{&p.Keyfile, &other.Keyfile},
+		{&p.Key, &other.Key},
+		{&p.Keystore, &other.Keystore},
+		{&p.Mode, &other.Mode},
+		{&p.Product, &other.Product},
+		{&p.ProductVersion, &other.ProductVersion},
+		{&p.Format, &other.Format},
+		{&p.FIPSPolicy, &other.FIPSPolicy},
+		{&p.Policy, &other.Policy},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+}
+
+// Config is the combined contents of one or more configuration files.
+type Config struct {
+	// DefaultProfile is used when no profile is requested.
+	DefaultProfile string `toml:"default_profile"`
+	// Profiles maps names to profiles.
+	Profiles map[string]Profile `toml:"profiles"`
+}
+
+// UserPath returns the location of the current user's configuration file,
+// which is $XDG_CONFIG_HOME/rskey/config.toml or ~/.config/rskey/config.toml.
+func UserPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "rskey", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "rskey", "config.toml"), nil
+}
+
+// DefaultPaths returns the system and user configuration file paths, in the
+// order they should be loaded.
+func DefaultPaths() []string {
+	paths := []string{SystemPath}
+	if user, err := UserPath(); err == nil {
+		paths = append(paths, user)
+	}
+	return paths
+}
+
+// Load reads and combines the given configuration files, skipping any that do
+// not exist. Values in later files override those in earlier ones, field by
+// field.
+func Load(paths ...string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]Profile{}}
+	for _, path := range paths {
+		var file Config
+		md, err := toml.DecodeFile(path, &file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("%s: unknown setting %q", path, undecoded[0].String())
+		}
+		if file.DefaultProfile != "" {
+			cfg.DefaultProfile = file.DefaultProfile
+		}
+		for name, p := range file.Profiles {
+			merged := cfg.Profiles[name]
+			merged.merge(p)
+			cfg.Profiles[name] = merged
+		}
+	}
+	return cfg, nil
+}
+
+// Profile returns the named profile, or the default profile if name is empty.
+// If there is no default profile, an empty profile is returned.
+func (c *Config) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+		if name == "" {
+			return Profile{}, nil
+		}
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("%w %q", ErrUnknownProfile, name)
+	}
+	return p, nil
+}
+
+// EnvName returns the environment variable that overrides a flag.
+func EnvName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// alternatives are groups of flags that select the same thing in different
+// ways. Only those from the source with the highest precedence are applied, so
+// that, for example, --key on the command line overrides RSKEY_KEYFILE.
+var alternatives = [][]string{{"keyfile", "key"}}
+
+// The sources of flag values, from the highest precedence to the lowest.
+const (
+	fromFlag = iota
+	fromEnv
+	fromProfile
+)
+
+// sourceAnnotation marks the flags that Apply sets.
+const sourceAnnotation = "rskey-config-source"
+
+// Apply sets each flag that was not given on the command line from its
+// environment variable, as returned by getenv, or failing that, from the
+// profile. The precedence is therefore flags, then the environment, then the
+// profile, then flag defaults. Flags set by Apply are not marked as changed;
+// use IsSet to tell whether a flag was given in any of these ways.
+func Apply(flags *pflag.FlagSet, p Profile, getenv func(string) string) error {
+	type setting struct {
+		value  string
+		source int
+	}
+	values := p.Flags()
+	settings := map[string]setting{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			settings[f.Name] = setting{f.Value.String(), fromFlag}
+		} else if env := getenv(EnvName(f.Name)); env != "" {
+			settings[f.Name] = setting{env, fromEnv}
+		} else if value, ok := values[f.Name]; ok {
+			settings[f.Name] = setting{value, fromProfile}
+		}
+	})
+	for _, group := range alternatives {
+		best := fromProfile
+		for _, name := range group {
+			if s, ok := settings[name]; ok {
+				best = min(best, s.source)
+			}
+		}
+		for _, name := range group {
+			if s, ok := settings[name]; ok && s.source > best {
+				delete(settings, name)
+			}
+		}
+	}
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		s, ok := settings[f.Name]
+		if err != nil || !ok || s.source == fromFlag {
+			return
+		}
+		if setErr := f.Value.Set(s.value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for %s: %w", s.value, f.Name, setErr)
+			return
+		}
+		source := "profile"
+		if s.source == fromEnv {
+			source = EnvName(f.Name)
+		}
+		if f.Annotations == nil {
+			f.Annotations = map[string][]string{}
+		}
+		f.Annotations[sourceAnnotation] = []string{source}
+	})
+	return err
+}
+
+// IsSet reports whether a flag was given on the command line, or set by Apply.
+func IsSet(f *pflag.Flag) bool {
+	return f.Changed || len(f.Annotations[sourceAnnotation]) > 0
+}
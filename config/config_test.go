@@ -0,0 +1,166 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/check.v1"
+)
+
+type ConfigSuite struct {
+	dir string
+}
+
+func (s *ConfigSuite) SetUpTest(c *check.C) {
+	s.dir = c.MkDir()
+}
+
+func (s *ConfigSuite) write(c *check.C, name, contents string) string {
+	path := filepath.Join(s.dir, name)
+	c.Assert(os.WriteFile(path, []byte(contents), 0600), check.IsNil)
+	return path
+}
+
+func (s *ConfigSuite) TestLoad(c *check.C) {
+	system := s.write(c, "system.toml", `
+default_profile = "ppm"
+
+[profiles.ppm]
+keyfile = "/var/lib/rstudio-pm/rstudio-pm.key"
+mode = "fips"
+
+[profiles.workbench]
+keyfile = "/etc/rstudio/secure-cookie-key"
+mode = "workbench"
+`)
+	user := s.write(c, "user.toml", `
+[profiles.ppm]
+fips_policy = "enforce"
+
+[profiles.local]
+product = "connect"
+product_version = "2024.01.0"
+key = "dev"
+keystore = "/home/me/keys"
+format = "json"
+policy = "/home/me/policy.json"
+`)
+	cfg, err := Load(system, filepath.Join(s.dir, "missing.toml"), user)
+	c.Assert(err, check.IsNil)
+	c.Check(cfg.DefaultProfile, check.Equals, "ppm")
+
+	p, err := cfg.Profile("")
+	c.Assert(err, check.IsNil)
+	c.Check(p, check.Equals, Profile{
+		Keyfile:    "/var/lib/rstudio-pm/rstudio-pm.key",
+		Mode:       "fips",
+		FIPSPolicy: "enforce",
+	})
+	p, err = cfg.Profile("local")
+	c.Assert(err, check.IsNil)
+	c.Check(p.Flags(), check.DeepEquals, map[string]string{
+		"product":         "connect",
+		"product-version": "2024.01.0",
+		"key":             "dev",
+		"keystore":        "/home/me/keys",
+		"format":          "json",
+		"policy":          "/home/me/policy.json",
+	})
+	_, err = cfg.Profile("other")
+	c.Check(errors.Is(err, ErrUnknownProfile), check.Equals, true)
+	c.Check(err, check.ErrorMatches, `unknown profile "other"`)
+}
+
+func (s *ConfigSuite) TestLoadEmpty(c *check.C) {
+	cfg, err := Load(filepath.Join(s.dir, "missing.toml"))
+	c.Assert(err, check.IsNil)
+	p, err := cfg.Profile("")
+	c.Assert(err, check.IsNil)
+	c.Check(p, check.Equals, Profile{})
+}
+
+func (s *ConfigSuite) TestLoadErrors(c *check.C) {
+	_, err := Load(s.write(c, "typo.toml", "[profiles.ppm]\nkey_file = \"x\"\n"))
+	c.Check(err, check.ErrorMatches, `.*typo.toml: unknown setting "profiles.ppm.key_file"`)
+	_, err = Load(s.write(c, "syntax.toml", "[profiles.ppm\n"))
+	c.Check(err, check.ErrorMatches, `.*syntax.toml: .*`)
+}
+
+func (s *ConfigSuite) TestApply(c *check.C) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("keyfile", "", "")
+	flags.String("mode", "default", "")
+	flags.String("format", "text", "")
+	flags.String("fips-policy", "auto", "")
+	c.Assert(flags.Parse([]string{"--keyfile=flag.key"}), check.IsNil)
+
+	env := map[string]string{"RSKEY_MODE": "workbench", "RSKEY_KEYFILE": "env.key"}
+	p := Profile{Keyfile: "profile.key", Mode: "fips", FIPSPolicy: "enforce"}
+	c.Assert(Apply(flags, p, func(k string) string { return env[k] }), check.IsNil)
+
+	get := func(name string) string { return flags.Lookup(name).Value.String() }
+	c.Check(get("keyfile"), check.Equals, "flag.key")
+	c.Check(get("mode"), check.Equals, "workbench")
+	c.Check(get("fips-policy"), check.Equals, "enforce")
+	c.Check(get("format"), check.Equals, "text")
+	c.Check(flags.Lookup("format").Changed, check.Equals, false)
+	c.Check(IsSet(flags.Lookup("format")), check.Equals, false)
+	// Values from the environment or the profile are not marked as changed.
+	c.Check(flags.Lookup("mode").Changed, check.Equals, false)
+	c.Check(IsSet(flags.Lookup("mode")), check.Equals, true)
+	c.Check(IsSet(flags.Lookup("keyfile")), check.Equals, true)
+}
+
+func (s *ConfigSuite) TestApplyAlternatives(c *check.C) {
+	newFlags := func(args ...string) *pflag.FlagSet {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("keyfile", "", "")
+		flags.String("key", "", "")
+		c.Assert(flags.Parse(args), check.IsNil)
+		return flags
+	}
+	get := func(flags *pflag.FlagSet, name string) string { return flags.Lookup(name).Value.String() }
+	env := map[string]string{"RSKEY_KEYFILE": "env.key"}
+	getenv := func(k string) string { return env[k] }
+
+	// --key on the command line overrides a key file from the environment.
+	flags := newFlags("--key=label")
+	c.Assert(Apply(flags, Profile{Keyfile: "profile.key"}, getenv), check.IsNil)
+	c.Check(get(flags, "keyfile"), check.Equals, "")
+	c.Check(get(flags, "key"), check.Equals, "label")
+
+	// The environment overrides a key in the profile.
+	flags = newFlags()
+	c.Assert(Apply(flags, Profile{Key: "profile"}, getenv), check.IsNil)
+	c.Check(get(flags, "keyfile"), check.Equals, "env.key")
+	c.Check(get(flags, "key"), check.Equals, "")
+	c.Check(IsSet(flags.Lookup("key")), check.Equals, false)
+}
+
+func (s *ConfigSuite) TestApplyInvalid(c *check.C) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Int("batch-size", 100, "")
+	err := Apply(flags, Profile{}, func(k string) string {
+		if k == "RSKEY_BATCH_SIZE" {
+			return "many"
+		}
+		return ""
+	})
+	c.Check(err, check.ErrorMatches, `invalid value "many" for batch-size: .*`)
+}
+
+func (s *ConfigSuite) TestEnvName(c *check.C) {
+	c.Check(EnvName("fips-policy"), check.Equals, "RSKEY_FIPS_POLICY")
+	c.Check(EnvName("keyfile"), check.Equals, "RSKEY_KEYFILE")
+}
+
+func Test(t *testing.T) {
+	check.Suite(&ConfigSuite{})
+	check.TestingT(t)
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"crypto/cipher"
+)
+
+// AEAD returns the AES-256-GCM construction used by EncryptBytesFIPS as a
+// cipher.AEAD, for use with libraries that expect the standard interface.
+//
+// The AEAD has a 12-byte nonce and 16 bytes of overhead. Output from
+// EncryptBytesFIPS is exactly
+//
+//	base64(0x02 || nonce || aead.Seal(nil, nonce, plaintext, nil))
+//
+// so data sealed with the adapter using no additional data can be turned into
+// a ciphertext accepted by Decrypt (and by Connect and Package Manager) by
+// adding the version byte and nonce prefix. Data sealed with additional data
+// can only be opened through the AEAD itself.
+//
+// The current Policy is applied when the AEAD is created, as it is for
+// encryption, so AEAD returns a *PolicyError if the key has been revoked or
+// AES-256-GCM is not allowed.
+func (k *Key) AEAD() (cipher.AEAD, error) {
+	if err := k.checkEncrypt(AlgorithmAESGCM); err != nil {
+		return nil, err
+	}
+	return k.newAESGCM(), nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"encoding/base64"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestAEAD(c *check.C) {
+	key, _ := NewKey()
+	aead, err := key.AEAD()
+	c.Assert(err, check.IsNil)
+	c.Check(aead.NonceSize(), check.Equals, 12)
+	c.Check(aead.Overhead(), check.Equals, 16)
+
+	// Output from EncryptBytesFIPS should open with the adapter once the
+	// version byte and nonce are removed.
+	cipher, err := key.EncryptFIPS("some secret")
+	c.Check(err, check.IsNil)
+	buf, _ := base64.StdEncoding.DecodeString(cipher)
+	c.Assert(len(buf) > 13, check.Equals, true)
+	c.Check(buf[0], check.Equals, byte(2))
+	out, err := aead.Open(nil, buf[1:13], buf[13:], nil)
+	c.Check(err, check.IsNil)
+	c.Check(string(out), check.Equals, "some secret")
+
+	// And the reverse: adding the prefix to the adapter's output should
+	// yield something Decrypt accepts.
+	nonce := make([]byte, aead.NonceSize())
+	sealed := aead.Seal(nil, nonce, []byte("another secret"), nil)
+	payload := append([]byte{2}, append(nonce, sealed...)...)
+	text, err := key.Decrypt(base64.StdEncoding.EncodeToString(payload))
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "another secret")
+
+	// Additional data must match when opening.
+	sealed = aead.Seal(nil, nonce, []byte("some secret"), []byte("context"))
+	_, err = aead.Open(nil, nonce, sealed, []byte("other context"))
+	c.Check(err, check.Not(check.IsNil))
+	out, err = aead.Open(nil, nonce, sealed, []byte("context"))
+	c.Check(err, check.IsNil)
+	c.Check(string(out), check.Equals, "some secret")
+}
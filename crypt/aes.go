@@ -8,6 +8,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"io"
 )
 
 const (
@@ -17,28 +18,44 @@ const (
 )
 
 // EncryptFIPS produces base64-encoded cipher text for the given payload and key
-// using a FIPS-compatible algorithm. It never returns an error.
+// using a FIPS-compatible algorithm. It only returns an error if this is
+// forbidden by the current Policy.
 func (k *Key) EncryptFIPS(s string) (string, error) {
 	return k.EncryptBytesFIPS([]byte(s))
 }
 
 // EncryptBytesFIPS produces base64-encoded cipher text for the given bytes and
-// key using a FIPS-compatible algorithm. It never returns an error.
+// key using a FIPS-compatible algorithm. It only returns an error if this is
+// forbidden by the current Policy.
 func (k *Key) EncryptBytesFIPS(bytes []byte) (string, error) {
-	return base64.StdEncoding.EncodeToString(k.encryptAES(bytes)), nil
+	return k.encryptBytesFIPS(rand.Reader, bytes)
 }
 
-func (k *Key) encryptAES(bytes []byte) []byte {
+func (k *Key) encryptBytesFIPS(r io.Reader, bytes []byte) (string, error) {
+	if err := k.checkEncrypt(AlgorithmAESGCM); err != nil {
+		return "", err
+	}
+	output, err := k.encryptAES(r, bytes)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(output), nil
+}
+
+func (k *Key) encryptAES(r io.Reader, bytes []byte) ([]byte, error) {
 	nonce := make([]byte, 12)
-	// As of Go 1.24, rand.Read() aborts rather than returning an error.
+	// As of Go 1.24, rand.Read() aborts rather than returning an error, but
+	// other readers may not.
 	// See: https://go.dev/issue/66821
-	_, _ = rand.Read(nonce)
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return nil, err
+	}
 	aead := k.newAESGCM()
 	output := aead.Seal(nil, nonce, bytes, nil)
 	output = append(nonce, output...)
 	// Append a version prefix.
 	output = append([]byte{2}, output...)
-	return output
+	return output, nil
 }
 
 func (k *Key) decryptAES(buf []byte) ([]byte, error) {
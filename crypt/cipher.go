@@ -0,0 +1,129 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownMode reports a mode that has not been registered.
+var ErrUnknownMode = errors.New("Unknown encryption mode")
+
+// Cipher is implemented by keys that can encrypt and decrypt secrets in the
+// format used by one of Posit's products.
+type Cipher interface {
+	// Encrypt produces cipher text for the given payload, or an error if
+	// one cannot be created.
+	Encrypt(s string) (string, error)
+	// Decrypt takes cipher text and returns the original clear text, or an
+	// error.
+	Decrypt(s string) (string, error)
+	// Fingerprint returns a string that can be used to identify the key.
+	Fingerprint() string
+	// Mode returns the name of the mode the key was registered under.
+	Mode() string
+}
+
+// A Loader reads a key for a particular mode from an io.Reader.
+type Loader func(src io.Reader) (Cipher, error)
+
+// ModeInfo describes a registered mode.
+type ModeInfo struct {
+	Name        string
+	Description string
+	loader      Loader
+}
+
+var (
+	modesMu sync.RWMutex
+	modes   = make(map[string]ModeInfo)
+)
+
+// RegisterMode makes a key format available under the given mode name. It is
+// intended to be called from the init function of packages implementing a
+// product's format, and panics if the name is already registered or loader is
+// nil.
+func RegisterMode(name, description string, loader Loader) {
+	modesMu.Lock()
+	defer modesMu.Unlock()
+	if loader == nil {
+		panic("crypt: RegisterMode loader is nil")
+	}
+	if _, dup := modes[name]; dup {
+		panic("crypt: RegisterMode called twice for mode " + name)
+	}
+	modes[name] = ModeInfo{name, description, loader}
+}
+
+// Modes returns the registered modes, sorted by name.
+func Modes() []ModeInfo {
+	modesMu.RLock()
+	defer modesMu.RUnlock()
+	out := make([]ModeInfo, 0, len(modes))
+	for _, m := range modes {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// LoadCipher reads a key from an io.Reader using the loader registered for the
+// given mode.
+func LoadCipher(mode string, src io.Reader) (Cipher, error) {
+	modesMu.RLock()
+	m, ok := modes[mode]
+	modesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMode, mode)
+	}
+	return m.loader(src)
+}
+
+// Mode implements Cipher. Keys used directly always use the "default" mode.
+func (k *Key) Mode() string {
+	return "default"
+}
+
+// FIPSCipher returns a Cipher that always encrypts using EncryptFIPS.
+func (k *Key) FIPSCipher() Cipher {
+	return fipsCipher{k, rand.Reader}
+}
+
+// fipsCipher is a Key that encrypts with a FIPS-compatible algorithm.
+type fipsCipher struct {
+	*Key
+	rand io.Reader
+}
+
+// Encrypt implements Cipher.
+func (f fipsCipher) Encrypt(s string) (string, error) {
+	return f.encryptBytesFIPS(f.rand, []byte(s))
+}
+
+// Mode implements Cipher.
+func (f fipsCipher) Mode() string {
+	return "fips"
+}
+
+func init() {
+	RegisterMode("default",
+		"Posit Connect/Package Manager keys, using the default algorithm",
+		func(src io.Reader) (Cipher, error) {
+			return NewKeyFromReader(src)
+		})
+	RegisterMode("fips",
+		"Posit Connect/Package Manager keys, using AES-256-GCM",
+		func(src io.Reader) (Cipher, error) {
+			key, err := NewKeyFromReader(src)
+			if err != nil {
+				return nil, err
+			}
+			return key.FIPSCipher(), nil
+		})
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestModes(c *check.C) {
+	names := []string{}
+	for _, m := range Modes() {
+		names = append(names, m.Name)
+		c.Check(m.Description, check.Not(check.Equals), "")
+	}
+	c.Check(names, check.DeepEquals, []string{"default", "fips"})
+
+	_, err := LoadCipher("unknown", strings.NewReader(sampleKey))
+	c.Check(err, check.ErrorMatches, `Unknown encryption mode: "unknown"`)
+
+	_, err = LoadCipher("fips", strings.NewReader("too short"))
+	c.Check(err, check.Equals, ErrInvalidKeyLength)
+
+	c.Check(func() {
+		RegisterMode("default", "", func(io.Reader) (Cipher, error) { return nil, nil })
+	}, check.PanicMatches, `.*called twice.*`)
+}
+
+func (s *KeySuite) TestCiphers(c *check.C) {
+	def, err := LoadCipher("default", strings.NewReader(sampleKey))
+	c.Assert(err, check.IsNil)
+	c.Check(def.Mode(), check.Equals, "default")
+	c.Check(def.Fingerprint(), check.Equals, sampleHash)
+
+	fips, err := LoadCipher("fips", strings.NewReader(sampleKey))
+	c.Assert(err, check.IsNil)
+	c.Check(fips.Mode(), check.Equals, "fips")
+	c.Check(fips.Fingerprint(), check.Equals, sampleHash)
+
+	// The FIPS cipher always produces version-2 payloads.
+	cipher, err := fips.Encrypt("some secret")
+	c.Check(err, check.IsNil)
+	buf, _ := base64.StdEncoding.DecodeString(cipher)
+	c.Check(buf[0], check.Equals, byte(2))
+
+	// Both ciphers share a key, so can decrypt each other's output.
+	text, err := def.Decrypt(cipher)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "some secret")
+	cipher, err = def.Encrypt("some secret")
+	c.Check(err, check.IsNil)
+	text, err = fips.Decrypt(cipher)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "some secret")
+}
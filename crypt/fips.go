@@ -6,15 +6,28 @@
 
 package crypt
 
+import (
+	"crypto/cipher"
+	"io"
+)
+
 // When true, this package has been built in "FIPS mode". Attempts to use
 // encryption algorithms not permissible under FIPS-140 regulations will always
 // fail, and encryption will use AES-256-GCM by default.
+//
+// FIPS mode can also be enabled at runtime; see FIPSEnabled().
 const FIPSMode = true
 
-func (k *Key) encryptSecretbox(bytes []byte) ([]byte, error) {
+func (k *Key) encryptSecretbox(r io.Reader, bytes []byte) ([]byte, error) {
 	return []byte{}, ErrFIPS
 }
 
 func (k *Key) decryptSecretbox(buf []byte) ([]byte, error) {
 	return []byte{}, ErrFIPS
 }
+
+// SecretboxAEAD returns the NaCl Secretbox construction used by EncryptBytes
+// as a cipher.AEAD. It returns ErrFIPS when running in FIPS mode.
+func (k *Key) SecretboxAEAD() (cipher.AEAD, error) {
+	return nil, ErrFIPS
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"crypto/fips140"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// FIPSPolicy controls when this package refuses to use algorithms that are not
+// permissible under FIPS 140 regulations.
+type FIPSPolicy int32
+
+const (
+	// FIPSAuto enforces FIPS mode when the package was built with the "fips"
+	// tag or when Go's FIPS 140-3 module is enabled at runtime (for example
+	// with GODEBUG=fips140=on). This is the default.
+	FIPSAuto FIPSPolicy = iota
+	// FIPSEnforce always enforces FIPS mode.
+	FIPSEnforce
+)
+
+var fipsPolicy atomic.Int32
+
+// String implements fmt.Stringer.
+func (p FIPSPolicy) String() string {
+	switch p {
+	case FIPSAuto:
+		return "auto"
+	case FIPSEnforce:
+		return "enforce"
+	}
+	return fmt.Sprintf("FIPSPolicy(%d)", int32(p))
+}
+
+// ParseFIPSPolicy returns the policy with the given name, either "auto" or
+// "enforce".
+func ParseFIPSPolicy(s string) (FIPSPolicy, error) {
+	switch s {
+	case "auto", "":
+		return FIPSAuto, nil
+	case "enforce":
+		return FIPSEnforce, nil
+	}
+	return FIPSAuto, fmt.Errorf("unknown FIPS policy %q", s)
+}
+
+// SetFIPSPolicy changes the FIPS policy for the whole process. It is safe to
+// call concurrently with encryption and decryption.
+func SetFIPSPolicy(p FIPSPolicy) {
+	fipsPolicy.Store(int32(p))
+}
+
+// FIPSEnabled reports whether the package is currently running in "FIPS mode".
+// When true, attempts to use encryption algorithms not permissible under
+// FIPS-140 regulations will fail with ErrFIPS, and encryption will use
+// AES-256-GCM by default.
+func FIPSEnabled() bool {
+	return FIPSMode || FIPSPolicy(fipsPolicy.Load()) == FIPSEnforce ||
+		fips140.Enabled()
+}
+
+// FIPSStatus describes the cryptographic module in use and why FIPS mode is or
+// is not enabled.
+type FIPSStatus struct {
+	// Enabled is the result of FIPSEnabled().
+	Enabled bool
+	// BuildTag reports whether the package was built with the "fips" tag.
+	BuildTag bool
+	// Module reports whether Go's FIPS 140-3 module is enabled at runtime.
+	Module bool
+	// ModuleVersion is the GOFIPS140 setting used to build the binary, such
+	// as "off", "latest", or a frozen module version like "v1.0.0". It is
+	// empty when build information is unavailable.
+	ModuleVersion string
+	// Policy is the current FIPSPolicy.
+	Policy FIPSPolicy
+}
+
+// CurrentFIPSStatus reports the current FIPSStatus.
+func CurrentFIPSStatus() FIPSStatus {
+	status := FIPSStatus{
+		Enabled:  FIPSEnabled(),
+		BuildTag: FIPSMode,
+		Module:   fips140.Enabled(),
+		Policy:   FIPSPolicy(fipsPolicy.Load()),
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		status.ModuleVersion = "off"
+		for _, s := range info.Settings {
+			if s.Key == "GOFIPS140" {
+				status.ModuleVersion = s.Value
+			}
+		}
+	}
+	return status
+}
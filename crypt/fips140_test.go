@@ -0,0 +1,62 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"crypto/fips140"
+	"encoding/base64"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestFIPSPolicy(c *check.C) {
+	p, err := ParseFIPSPolicy("enforce")
+	c.Check(err, check.IsNil)
+	c.Check(p, check.Equals, FIPSEnforce)
+	c.Check(p.String(), check.Equals, "enforce")
+	p, err = ParseFIPSPolicy("")
+	c.Check(err, check.IsNil)
+	c.Check(p, check.Equals, FIPSAuto)
+	_, err = ParseFIPSPolicy("sometimes")
+	c.Check(err, check.ErrorMatches, `unknown FIPS policy "sometimes"`)
+
+	c.Check(FIPSEnabled(), check.Equals, FIPSMode || fips140.Enabled())
+	status := CurrentFIPSStatus()
+	c.Check(status.Enabled, check.Equals, FIPSEnabled())
+	c.Check(status.BuildTag, check.Equals, FIPSMode)
+	c.Check(status.Module, check.Equals, fips140.Enabled())
+	c.Check(status.Policy, check.Equals, FIPSAuto)
+
+	// A secretbox payload encrypted with a known key. This uses the
+	// version prefix, because a versionless payload might happen to start
+	// with the AES version byte.
+	key, _ := NewKey()
+	var payload string
+	if !FIPSEnabled() {
+		payload, err = key.encryptVersioned("some secret")
+		c.Assert(err, check.IsNil)
+	}
+
+	SetFIPSPolicy(FIPSEnforce)
+	defer SetFIPSPolicy(FIPSAuto)
+	c.Check(FIPSEnabled(), check.Equals, true)
+	c.Check(CurrentFIPSStatus().Policy, check.Equals, FIPSEnforce)
+
+	// Encryption should now always use AES.
+	cipher, err := key.Encrypt("some secret")
+	c.Check(err, check.IsNil)
+	buf, _ := base64.StdEncoding.DecodeString(cipher)
+	c.Check(buf[0], check.Equals, byte(2))
+	text, err := key.Decrypt(cipher)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "some secret")
+
+	// And secretbox is refused.
+	_, err = key.SecretboxAEAD()
+	c.Check(err, check.Equals, ErrFIPS)
+	if payload != "" {
+		_, err = key.Decrypt(payload)
+		c.Check(err, check.Equals, ErrFIPS)
+	}
+}
@@ -11,3 +11,9 @@ import "gopkg.in/check.v1"
 func (s *KeySuite) TestFIPSMode(c *check.C) {
 	c.Check(FIPSMode, check.Equals, true)
 }
+
+func (s *KeySuite) TestSecretboxAEAD(c *check.C) {
+	key, _ := NewKey()
+	_, err := key.SecretboxAEAD()
+	c.Check(err, check.Equals, ErrFIPS)
+}
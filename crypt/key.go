@@ -31,7 +31,7 @@ var (
 	// given Key via Decrypt().
 	ErrFailedToDecrypt = errors.New("Decryption failed")
 	// ErrFIPS reports encryption or decryption failures caused by running
-	// in FIPS mode.
+	// in FIPS mode. See FIPSEnabled().
 	ErrFIPS = errors.New("Non-AES algorithms cannot be used when running in FIPS mode")
 )
 
@@ -77,6 +77,11 @@ func NewKeyFromBytes(src []byte) (*Key, error) {
 
 	var key Key
 	copy(key[:], data[0:512])
+	if p := CurrentPolicy(); p != nil {
+		if err := p.AllowsKey(key.Fingerprint()); err != nil {
+			return nil, err
+		}
+	}
 	return &key, nil
 }
 
@@ -113,12 +118,24 @@ func (k *Key) Encrypt(s string) (string, error) {
 // EncryptBytes produces base64-encoded cipher text for the given bytes and key,
 // or an error if one cannot be created.
 func (k *Key) EncryptBytes(bytes []byte) (string, error) {
-	var output []byte
-	if FIPSMode {
-		output := k.encryptAES(bytes)
-		return base64.StdEncoding.EncodeToString(output), nil
+	return k.encryptBytes(rand.Reader, bytes)
+}
+
+// EncryptsSecretbox reports whether keys in the default mode encrypt with
+// secretbox. In FIPS mode, or when the crypto policy does not allow secretbox,
+// they encrypt with AES-256-GCM instead.
+func EncryptsSecretbox() bool {
+	return !FIPSEnabled() && CurrentPolicy().AllowsEncryption(AlgorithmSecretbox) == nil
+}
+
+func (k *Key) encryptBytes(r io.Reader, bytes []byte) (string, error) {
+	if !EncryptsSecretbox() {
+		return k.encryptBytesFIPS(r, bytes)
 	}
-	output, err := k.encryptSecretbox(bytes)
+	if err := k.checkEncrypt(AlgorithmSecretbox); err != nil {
+		return "", err
+	}
+	output, err := k.encryptSecretbox(r, bytes)
 	if err != nil {
 		return "", err
 	}
@@ -129,7 +146,10 @@ func (k *Key) EncryptBytes(bytes []byte) (string, error) {
 // version for the given payload and key, or an error if one cannot be created.
 // This emulates the format used by some implementations.
 func (k *Key) encryptVersioned(s string) (string, error) {
-	output, err := k.encryptSecretbox([]byte(s))
+	if err := k.checkEncrypt(AlgorithmSecretbox); err != nil {
+		return "", err
+	}
+	output, err := k.encryptSecretbox(rand.Reader, []byte(s))
 	if err != nil {
 		return "", err
 	}
@@ -154,6 +174,14 @@ func (k *Key) DecryptBytes(s string) ([]byte, error) {
 	if len(buf) < 1 {
 		return []byte{}, ErrPayLoadTooShort
 	}
+	if p := CurrentPolicy(); p != nil {
+		if err := p.AllowsKey(k.Fingerprint()); err != nil {
+			return []byte{}, err
+		}
+		if p.Strict() {
+			return k.decryptStrict(p, buf)
+		}
+	}
 	// Some implementations use a version-prefixed cipher text. In order to
 	// handle the (unlikely but possible) case where a versionless payload
 	// *just happens* to start with a valid version byte, we must also try
@@ -161,18 +189,39 @@ func (k *Key) DecryptBytes(s string) ([]byte, error) {
 	switch buf[0] {
 	case byte(1):
 		str, err := k.decryptSecretbox(buf[1:])
-		if err == nil || FIPSMode {
+		if err == nil || FIPSEnabled() {
 			return str, err
 		}
 	case byte(2):
 		str, err := k.decryptAES(buf)
-		if err == nil || FIPSMode {
+		if err == nil || FIPSEnabled() {
 			return str, err
 		}
 	}
 	return k.decryptSecretbox(buf)
 }
 
+// decryptStrict decrypts cipher text according to its version byte, without
+// any fallback, if the policy allows that version.
+func (k *Key) decryptStrict(p *Policy, buf []byte) ([]byte, error) {
+	switch buf[0] {
+	case byte(1):
+		if err := p.AllowsDecryption(FormatV1); err != nil {
+			return []byte{}, err
+		}
+		return k.decryptSecretbox(buf[1:])
+	case byte(2):
+		if err := p.AllowsDecryption(FormatV2); err != nil {
+			return []byte{}, err
+		}
+		return k.decryptAES(buf)
+	}
+	if err := p.AllowsDecryption(FormatLegacy); err != nil {
+		return []byte{}, err
+	}
+	return k.decryptSecretbox(buf)
+}
+
 // Fingerprint returns a string that can be used to identify this key.
 //
 // The fingerprint is not appropriate for cryptographic use. It is used as a
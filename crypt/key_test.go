@@ -110,7 +110,7 @@ func (s *KeySuite) TestEncryption(c *check.C) {
 
 	// These payloads do not have a the FIPS version prefix, so they will
 	// generate a different error in FIPS mode.
-	if !FIPSMode {
+	if !FIPSEnabled() {
 		_, err = key.Decrypt("ycKfTfYlVaOnsypb")
 		c.Check(err, check.Equals, ErrPayLoadTooShort)
 
@@ -133,7 +133,7 @@ func (s *KeySuite) TestEncryption(c *check.C) {
 }
 
 func (s *KeySuite) TestVersionedEncryption(c *check.C) {
-	if FIPSMode {
+	if FIPSEnabled() {
 		c.ExpectFailure("NaCl encryption will not work under FIPS")
 	}
 	key, _ := NewKey()
@@ -0,0 +1,209 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeyringOptions configure a Keyring.
+type KeyringOptions struct {
+	// GracePeriod is how long a key replaced by Rotate() remains available
+	// for decryption. When zero, replaced keys are discarded immediately.
+	GracePeriod time.Duration
+	// OnRotate, if set, is called with the fingerprints of the old and new
+	// active keys whenever the active key changes.
+	OnRotate func(old, new string)
+}
+
+// Keyring is a Cipher that encrypts with a single active key but can decrypt
+// with previous keys, which makes it possible to rotate keys in long-running
+// services without a restart. It is safe for concurrent use.
+type Keyring struct {
+	opts KeyringOptions
+	// now is replaced in tests.
+	now func() time.Time
+
+	mu       sync.RWMutex
+	active   Cipher
+	previous []previousKey
+}
+
+// previousKey is a key that is only used for decryption.
+type previousKey struct {
+	Cipher
+	// The zero time means the key never expires.
+	expires time.Time
+}
+
+// NewKeyring returns a Keyring with the given active key.
+func NewKeyring(active Cipher, opts KeyringOptions) *Keyring {
+	return &Keyring{opts: opts, now: time.Now, active: active}
+}
+
+// Active returns the key currently used for encryption.
+func (r *Keyring) Active() Cipher {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+// Encrypt implements Cipher, using the active key.
+func (r *Keyring) Encrypt(s string) (string, error) {
+	return r.Active().Encrypt(s)
+}
+
+// Decrypt implements Cipher. It tries the active key first and then previous
+// keys, most recent first. If none succeed, the active key's error is
+// returned.
+func (r *Keyring) Decrypt(s string) (string, error) {
+	text, _, err := r.DecryptKey(s)
+	return text, err
+}
+
+// DecryptKey is like Decrypt, but also returns the key that decrypted s.
+func (r *Keyring) DecryptKey(s string) (string, Cipher, error) {
+	r.mu.RLock()
+	active := r.active
+	previous := r.previous
+	r.mu.RUnlock()
+	text, err := active.Decrypt(s)
+	if err == nil {
+		return text, active, nil
+	}
+	now := r.now()
+	for _, p := range previous {
+		if !p.expires.IsZero() && now.After(p.expires) {
+			continue
+		}
+		if out, perr := p.Decrypt(s); perr == nil {
+			return out, p.Cipher, nil
+		}
+	}
+	return "", nil, err
+}
+
+// Fingerprint implements Cipher, returning the active key's fingerprint.
+func (r *Keyring) Fingerprint() string {
+	return r.Active().Fingerprint()
+}
+
+// Mode implements Cipher, returning the active key's mode.
+func (r *Keyring) Mode() string {
+	return r.Active().Mode()
+}
+
+// AddDecryptOnly makes a key available for decryption, without an expiry.
+func (r *Keyring) AddDecryptOnly(c Cipher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.previous = append(r.previous, previousKey{Cipher: c})
+}
+
+// Fingerprints returns the fingerprints of every key that can currently be
+// used for decryption, starting with the active key.
+func (r *Keyring) Fingerprints() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	now := r.now()
+	out := []string{r.active.Fingerprint()}
+	for _, p := range r.previous {
+		if p.expires.IsZero() || !now.After(p.expires) {
+			out = append(out, p.Fingerprint())
+		}
+	}
+	return out
+}
+
+// Rotate atomically replaces the active key. The old key remains available
+// for decryption during the grace period. Rotating to a key with the same
+// fingerprint as the active key does nothing.
+func (r *Keyring) Rotate(c Cipher) {
+	r.mu.Lock()
+	old := r.active
+	if old.Fingerprint() == c.Fingerprint() {
+		r.mu.Unlock()
+		return
+	}
+	now := r.now()
+	// Build a new slice so that concurrent readers are unaffected.
+	previous := make([]previousKey, 0, len(r.previous)+1)
+	if r.opts.GracePeriod > 0 {
+		previous = append(previous, previousKey{old, now.Add(r.opts.GracePeriod)})
+	}
+	for _, p := range r.previous {
+		if p.Fingerprint() == c.Fingerprint() {
+			continue
+		}
+		if p.expires.IsZero() || !now.After(p.expires) {
+			previous = append(previous, p)
+		}
+	}
+	r.active = c
+	r.previous = previous
+	r.mu.Unlock()
+	if r.opts.OnRotate != nil {
+		r.opts.OnRotate(old.Fingerprint(), c.Fingerprint())
+	}
+}
+
+// LoadKeyring returns a Keyring whose active key is read from a file using the
+// loader registered for the given mode.
+func LoadKeyring(path, mode string, opts KeyringOptions) (*Keyring, error) {
+	c, err := loadCipherFile(path, mode)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(c, opts), nil
+}
+
+func loadCipherFile(path, mode string) (Cipher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return LoadCipher(mode, bytes.NewReader(data))
+}
+
+// Watch polls a key file at the given interval and rotates the keyring when
+// the file contains a different key. The contents are compared on every tick,
+// so a key that is replaced without changing the file's size or modification
+// time is still noticed. Errors reading or parsing the file, such as those
+// caused by a partially-written file, are passed to onError (if it is not nil)
+// and the active key is kept. Watch blocks until ctx is done.
+func (r *Keyring) Watch(ctx context.Context, path, mode string, interval time.Duration, onError func(error)) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	var last []byte
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		data, err := os.ReadFile(path)
+		if err == nil && last != nil && bytes.Equal(data, last) {
+			continue
+		}
+		var c Cipher
+		if err == nil {
+			last = data
+			c, err = LoadCipher(mode, bytes.NewReader(data))
+		}
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+			continue
+		}
+		r.Rotate(c)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestKeyring(c *check.C) {
+	k1, _ := NewKey()
+	k2, _ := NewKey()
+	k3, _ := NewKey()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rotations [][2]string
+	ring := NewKeyring(k1, KeyringOptions{
+		GracePeriod: time.Hour,
+		OnRotate: func(old, new string) {
+			rotations = append(rotations, [2]string{old, new})
+		},
+	})
+	ring.now = func() time.Time { return now }
+	c.Check(ring.Mode(), check.Equals, "default")
+	c.Check(ring.Fingerprint(), check.Equals, k1.Fingerprint())
+
+	c1, err := ring.Encrypt("first")
+	c.Assert(err, check.IsNil)
+
+	ring.Rotate(k2)
+	c.Check(ring.Active(), check.Equals, Cipher(k2))
+	c.Check(rotations, check.DeepEquals, [][2]string{{k1.Fingerprint(), k2.Fingerprint()}})
+	c.Check(ring.Fingerprints(), check.DeepEquals, []string{k2.Fingerprint(), k1.Fingerprint()})
+
+	// New data uses the new key, but old data can still be decrypted.
+	c2, err := ring.Encrypt("second")
+	c.Assert(err, check.IsNil)
+	text, err := k2.Decrypt(c2)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "second")
+	text, err = ring.Decrypt(c1)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "first")
+	text, key, err := ring.DecryptKey(c1)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "first")
+	c.Check(key, check.Equals, Cipher(k1))
+	_, key, _ = ring.DecryptKey(c2)
+	c.Check(key, check.Equals, Cipher(k2))
+
+	// Rotating to the same key does nothing.
+	ring.Rotate(k2)
+	c.Check(rotations, check.HasLen, 1)
+
+	// After the grace period, the old key is gone.
+	now = now.Add(2 * time.Hour)
+	_, err = ring.Decrypt(c1)
+	c.Check(err, check.Equals, ErrFailedToDecrypt)
+	c.Check(ring.Fingerprints(), check.DeepEquals, []string{k2.Fingerprint()})
+
+	// Decrypt-only keys never expire.
+	ring.AddDecryptOnly(k1)
+	ring.Rotate(k3)
+	now = now.Add(24 * time.Hour)
+	c.Check(ring.Fingerprints(), check.DeepEquals, []string{k3.Fingerprint(), k1.Fingerprint()})
+	text, err = ring.Decrypt(c1)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "first")
+
+	// Without a grace period, replaced keys are dropped.
+	ring = NewKeyring(k1, KeyringOptions{})
+	ring.Rotate(k2)
+	_, err = ring.Decrypt(c1)
+	c.Check(err, check.Equals, ErrFailedToDecrypt)
+}
+
+func (s *KeySuite) TestKeyringConcurrency(c *check.C) {
+	keys := make([]*Key, 4)
+	for i := range keys {
+		keys[i], _ = NewKey()
+	}
+	ring := NewKeyring(keys[0], KeyringOptions{GracePeriod: time.Hour})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if i == 0 {
+					ring.Rotate(keys[j%len(keys)])
+					continue
+				}
+				cipher, err := ring.Encrypt("secret")
+				c.Check(err, check.IsNil)
+				text, err := ring.Decrypt(cipher)
+				c.Check(err, check.IsNil)
+				c.Check(text, check.Equals, "secret")
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func (s *KeySuite) TestKeyringWatch(c *check.C) {
+	dir := c.MkDir()
+	path := filepath.Join(dir, "rskey.key")
+	k1, _ := NewKey()
+	k2, _ := NewKey()
+	c.Assert(os.WriteFile(path, []byte(k1.HexString()), 0600), check.IsNil)
+
+	_, err := LoadKeyring(filepath.Join(dir, "missing"), "default", KeyringOptions{})
+	c.Check(os.IsNotExist(err), check.Equals, true)
+
+	rotated := make(chan [2]string, 1)
+	ring, err := LoadKeyring(path, "fips", KeyringOptions{
+		GracePeriod: time.Hour,
+		OnRotate: func(old, new string) {
+			rotated <- [2]string{old, new}
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(ring.Mode(), check.Equals, "fips")
+	c1, _ := ring.Encrypt("first")
+
+	errs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ring.Watch(ctx, path, "fips", 5*time.Millisecond, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+		close(done)
+	}()
+
+	// A partially-written file is reported but ignored.
+	c.Assert(os.WriteFile(path, []byte("partial"), 0600), check.IsNil)
+	select {
+	case err := <-errs:
+		c.Check(err, check.Equals, ErrInvalidKeyLength)
+	case <-time.After(5 * time.Second):
+		c.Fatal("timed out waiting for an error")
+	}
+	c.Check(ring.Fingerprint(), check.Equals, k1.Fingerprint())
+
+	c.Assert(os.WriteFile(path, []byte(k2.HexString()), 0600), check.IsNil)
+	select {
+	case r := <-rotated:
+		c.Check(r, check.Equals, [2]string{k1.Fingerprint(), k2.Fingerprint()})
+	case <-time.After(5 * time.Second):
+		c.Fatal("timed out waiting for rotation")
+	}
+	c.Check(ring.Fingerprint(), check.Equals, k2.Fingerprint())
+	text, err := ring.Decrypt(c1)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "first")
+
+	// A key replaced without changing the size or modification time is
+	// still noticed.
+	info, err := os.Stat(path)
+	c.Assert(err, check.IsNil)
+	k3, _ := NewKey()
+	c.Assert(os.WriteFile(path, []byte(k3.HexString()), 0600), check.IsNil)
+	c.Assert(os.Chtimes(path, info.ModTime(), info.ModTime()), check.IsNil)
+	select {
+	case r := <-rotated:
+		c.Check(r, check.Equals, [2]string{k2.Fingerprint(), k3.Fingerprint()})
+	case <-time.After(5 * time.Second):
+		c.Fatal("timed out waiting for rotation")
+	}
+
+	cancel()
+	<-done
+}
@@ -7,7 +7,8 @@
 package crypt
 
 import (
-	"crypto/rand"
+	"crypto/cipher"
+	"io"
 
 	"golang.org/x/crypto/nacl/secretbox"
 )
@@ -15,6 +16,8 @@ import (
 // When true, this package has been built in "FIPS mode". Attempts to use
 // encryption algorithms not permissible under FIPS-140 regulations will always
 // fail, and encryption will use AES-256-GCM by default.
+//
+// FIPS mode can also be enabled at runtime; see FIPSEnabled().
 const FIPSMode = false
 
 const (
@@ -22,17 +25,26 @@ const (
 	minimumSecretboxLength = secretbox.Overhead + 24
 )
 
-func (k *Key) encryptSecretbox(bytes []byte) ([]byte, error) {
+func (k *Key) encryptSecretbox(r io.Reader, bytes []byte) ([]byte, error) {
+	if FIPSEnabled() {
+		return []byte{}, ErrFIPS
+	}
 	var nonce [24]byte
-	// As of Go 1.24, rand.Read() aborts rather than returning an error.
+	// As of Go 1.24, rand.Read() aborts rather than returning an error, but
+	// other readers may not.
 	// See: https://go.dev/issue/66821
-	_, _ = rand.Read(nonce[:])
+	if _, err := io.ReadFull(r, nonce[:]); err != nil {
+		return []byte{}, err
+	}
 	output := secretbox.Seal(nil, bytes, &nonce, k.key32())
 	output = append(nonce[:], output...)
 	return output, nil
 }
 
 func (k *Key) decryptSecretbox(buf []byte) ([]byte, error) {
+	if FIPSEnabled() {
+		return []byte{}, ErrFIPS
+	}
 	if len(buf) < minimumSecretboxLength {
 		return []byte{}, ErrPayLoadTooShort
 	}
@@ -54,3 +66,70 @@ func (k *Key) key32() *[32]byte {
 	copy(key[:], k[0:32])
 	return &key
 }
+
+// SecretboxAEAD returns the NaCl Secretbox construction used by EncryptBytes
+// as a cipher.AEAD. It returns ErrFIPS when running in FIPS mode, and a
+// *PolicyError if the current Policy does not allow encryption with the key
+// and Secretbox.
+//
+// The AEAD has a 24-byte nonce and secretbox.Overhead bytes of overhead. When
+// not running in FIPS mode, output from EncryptBytes is exactly
+//
+//	base64(nonce || aead.Seal(nil, nonce, plaintext, nil))
+//
+// Secretbox has no notion of additional data, so Seal panics if it is given
+// any and Open always fails.
+func (k *Key) SecretboxAEAD() (cipher.AEAD, error) {
+	if FIPSEnabled() {
+		return nil, ErrFIPS
+	}
+	if err := k.checkEncrypt(AlgorithmSecretbox); err != nil {
+		return nil, err
+	}
+	return &secretboxAEAD{key: k.key32()}, nil
+}
+
+// secretboxAEAD adapts NaCl Secretbox to the cipher.AEAD interface.
+type secretboxAEAD struct {
+	key *[32]byte
+}
+
+// NonceSize implements cipher.AEAD.
+func (a *secretboxAEAD) NonceSize() int {
+	return 24
+}
+
+// Overhead implements cipher.AEAD.
+func (a *secretboxAEAD) Overhead() int {
+	return secretbox.Overhead
+}
+
+// Seal implements cipher.AEAD.
+func (a *secretboxAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != 24 {
+		panic("crypt: incorrect nonce length given to Secretbox")
+	}
+	if len(additionalData) != 0 {
+		panic("crypt: Secretbox does not support additional data")
+	}
+	var n [24]byte
+	copy(n[:], nonce)
+	return secretbox.Seal(dst, plaintext, &n, a.key)
+}
+
+// Open implements cipher.AEAD.
+func (a *secretboxAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != 24 {
+		panic("crypt: incorrect nonce length given to Secretbox")
+	}
+	if len(additionalData) != 0 {
+		return nil, ErrFailedToDecrypt
+	}
+	var n [24]byte
+	copy(n[:], nonce)
+	out, ok := secretbox.Open(dst, ciphertext, &n, a.key)
+	if !ok {
+		return nil, ErrFailedToDecrypt
+	}
+	return out, nil
+}
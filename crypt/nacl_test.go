@@ -6,8 +6,48 @@
 
 package crypt
 
-import "gopkg.in/check.v1"
+import (
+	"encoding/base64"
+
+	"gopkg.in/check.v1"
+)
 
 func (s *KeySuite) TestFIPSMode(c *check.C) {
 	c.Check(FIPSMode, check.Equals, false)
 }
+
+func (s *KeySuite) TestSecretboxAEAD(c *check.C) {
+	key, _ := NewKey()
+	if FIPSEnabled() {
+		_, err := key.SecretboxAEAD()
+		c.Check(err, check.Equals, ErrFIPS)
+		return
+	}
+	aead, err := key.SecretboxAEAD()
+	c.Assert(err, check.IsNil)
+	c.Check(aead.NonceSize(), check.Equals, 24)
+	c.Check(aead.Overhead(), check.Equals, 16)
+
+	// Output from EncryptBytes should open with the adapter once the nonce
+	// is removed.
+	cipher, err := key.Encrypt("some secret")
+	c.Check(err, check.IsNil)
+	buf, _ := base64.StdEncoding.DecodeString(cipher)
+	out, err := aead.Open(nil, buf[:24], buf[24:], nil)
+	c.Check(err, check.IsNil)
+	c.Check(string(out), check.Equals, "some secret")
+
+	// And the reverse.
+	nonce := make([]byte, aead.NonceSize())
+	sealed := aead.Seal(nonce, nonce, []byte("another secret"), nil)
+	text, err := key.Decrypt(base64.StdEncoding.EncodeToString(sealed))
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "another secret")
+
+	// Secretbox has no additional data.
+	_, err = aead.Open(nil, nonce, sealed[24:], []byte("context"))
+	c.Check(err, check.Equals, ErrFailedToDecrypt)
+	c.Check(func() {
+		aead.Seal(nil, nonce, []byte("some secret"), []byte("context"))
+	}, check.PanicMatches, `.*additional data`)
+}
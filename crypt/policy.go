@@ -0,0 +1,289 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Algorithms that a Policy can allow for encryption.
+const (
+	// AlgorithmSecretbox is NaCl secretbox, the default for Connect and
+	// Package Manager.
+	AlgorithmSecretbox = "secretbox"
+	// AlgorithmAESGCM is AES-256-GCM, as produced by EncryptFIPS().
+	AlgorithmAESGCM = "aes-256-gcm"
+	// AlgorithmAESCBC is AES-128-CBC, as used by Workbench.
+	AlgorithmAESCBC = "aes-128-cbc"
+)
+
+// Cipher text formats that a Policy can allow for decryption.
+const (
+	// FormatLegacy is unversioned secretbox cipher text.
+	FormatLegacy = "legacy"
+	// FormatV1 is secretbox cipher text with a version byte of 1.
+	FormatV1 = "v1"
+	// FormatV2 is AES-256-GCM cipher text with a version byte of 2.
+	FormatV2 = "v2"
+)
+
+var (
+	// ErrPolicy reports an operation forbidden by the current Policy. All
+	// policy errors are of type *PolicyError and wrap ErrPolicy.
+	ErrPolicy = errors.New("Forbidden by crypto policy")
+)
+
+// PolicyError describes a violation of the current Policy.
+type PolicyError struct {
+	// Rule is the name of the policy setting that was violated.
+	Rule string
+	// Reason explains the violation.
+	Reason string
+}
+
+// Error implements error.
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("%v (%s): %s", ErrPolicy, e.Rule, e.Reason)
+}
+
+// Unwrap returns ErrPolicy.
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicy
+}
+
+// Policy is an organization's restrictions on the algorithms, cipher text
+// formats, and keys that may be used. Empty settings impose no restrictions,
+// and a nil *Policy allows everything.
+//
+// Policies are usually read from a JSON document with LoadPolicy(), for
+// example:
+//
+//	{
+//	  "encrypt_algorithms": ["aes-256-gcm", "aes-128-cbc"],
+//	  "decrypt_formats": ["v2"],
+//	  "min_workbench_key_length": 36,
+//	  "min_workbench_key_entropy": 100,
+//	  "revoked_fingerprints": ["BFA25145"]
+//	}
+type Policy struct {
+	// EncryptAlgorithms lists the algorithms that may be used to encrypt.
+	// When secretbox is not allowed, Encrypt() uses AES-256-GCM instead, as
+	// in FIPS mode.
+	EncryptAlgorithms []string `json:"encrypt_algorithms,omitempty"`
+	// DecryptFormats lists the Connect and Package Manager cipher text
+	// formats that may be decrypted. When set, decryption is strict: the
+	// version byte is always trusted, rather than falling back to
+	// unversioned secretbox as DecryptBytes() otherwise does.
+	DecryptFormats []string `json:"decrypt_formats,omitempty"`
+	// MinWorkbenchKeyLength is the minimum length of a Workbench key, in
+	// bytes.
+	MinWorkbenchKeyLength int `json:"min_workbench_key_length,omitempty"`
+	// MinWorkbenchKeyEntropy is the minimum estimated entropy of a
+	// Workbench key, in bits.
+	MinWorkbenchKeyEntropy float64 `json:"min_workbench_key_entropy,omitempty"`
+	// RejectWeakWorkbenchKeys rejects Workbench keys that the workbench
+	// package's strength analysis finds weak.
+	RejectWeakWorkbenchKeys bool `json:"reject_weak_workbench_keys,omitempty"`
+	// RevokedFingerprints lists the fingerprints of keys that must not be
+	// used. They are compared without regard to case.
+	RevokedFingerprints []string `json:"revoked_fingerprints,omitempty"`
+}
+
+var currentPolicy atomic.Pointer[Policy]
+
+// LoadPolicy reads a JSON-encoded Policy and validates it.
+func LoadPolicy(r io.Reader) (*Policy, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %v", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// LoadPolicyFile is like LoadPolicy, but reads from a file.
+func LoadPolicyFile(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := LoadPolicy(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Validate checks that the policy only refers to known algorithms and
+// formats.
+func (p *Policy) Validate() error {
+	for _, a := range p.EncryptAlgorithms {
+		switch a {
+		case AlgorithmSecretbox, AlgorithmAESGCM, AlgorithmAESCBC:
+		default:
+			return fmt.Errorf("invalid policy: unknown algorithm %q", a)
+		}
+	}
+	for _, f := range p.DecryptFormats {
+		switch f {
+		case FormatLegacy, FormatV1, FormatV2:
+		default:
+			return fmt.Errorf("invalid policy: unknown cipher text format %q", f)
+		}
+	}
+	if p.MinWorkbenchKeyLength < 0 || p.MinWorkbenchKeyEntropy < 0 {
+		return errors.New("invalid policy: minimums cannot be negative")
+	}
+	return nil
+}
+
+// Intersect returns a policy that allows only what both p and other allow, for
+// example to let a user's policy tighten, but never relax, a system policy. It
+// returns an error if the two allow no encryption algorithms or cipher text
+// formats in common. Either policy may be nil.
+func (p *Policy) Intersect(other *Policy) (*Policy, error) {
+	if p == nil {
+		return other, nil
+	} else if other == nil {
+		return p, nil
+	}
+	out := &Policy{
+		MinWorkbenchKeyLength:   max(p.MinWorkbenchKeyLength, other.MinWorkbenchKeyLength),
+		MinWorkbenchKeyEntropy:  max(p.MinWorkbenchKeyEntropy, other.MinWorkbenchKeyEntropy),
+		RejectWeakWorkbenchKeys: p.RejectWeakWorkbenchKeys || other.RejectWeakWorkbenchKeys,
+	}
+	var err error
+	out.EncryptAlgorithms, err = intersect(p.EncryptAlgorithms, other.EncryptAlgorithms, "encryption algorithms")
+	if err != nil {
+		return nil, err
+	}
+	out.DecryptFormats, err = intersect(p.DecryptFormats, other.DecryptFormats, "cipher text formats")
+	if err != nil {
+		return nil, err
+	}
+	out.RevokedFingerprints = append(slices.Clone(p.RevokedFingerprints), other.RevokedFingerprints...)
+	return out, nil
+}
+
+// intersect returns the values in both lists, where an empty list allows
+// anything.
+func intersect(a, b []string, what string) ([]string, error) {
+	if len(a) == 0 {
+		return slices.Clone(b), nil
+	} else if len(b) == 0 {
+		return slices.Clone(a), nil
+	}
+	var out []string
+	for _, v := range a {
+		if slices.Contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("invalid policy: no %s are allowed by both policies", what)
+	}
+	return out, nil
+}
+
+// SetPolicy changes the Policy for the whole process, or removes it if p is
+// nil. It is safe to call concurrently with encryption and decryption.
+func SetPolicy(p *Policy) error {
+	if p != nil {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+	currentPolicy.Store(p)
+	return nil
+}
+
+// CurrentPolicy returns the Policy set by SetPolicy(), or nil.
+func CurrentPolicy() *Policy {
+	return currentPolicy.Load()
+}
+
+// AllowsEncryption returns a *PolicyError if the policy does not allow
+// encryption with the given algorithm.
+func (p *Policy) AllowsEncryption(algorithm string) error {
+	if p == nil || len(p.EncryptAlgorithms) == 0 ||
+		slices.Contains(p.EncryptAlgorithms, algorithm) {
+		return nil
+	}
+	return &PolicyError{"encrypt_algorithms",
+		fmt.Sprintf("%s encryption is not allowed", algorithm)}
+}
+
+// AllowsDecryption returns a *PolicyError if the policy does not allow
+// decryption of the given cipher text format.
+func (p *Policy) AllowsDecryption(format string) error {
+	if !p.Strict() || slices.Contains(p.DecryptFormats, format) {
+		return nil
+	}
+	return &PolicyError{"decrypt_formats",
+		fmt.Sprintf("%s cipher text cannot be decrypted", format)}
+}
+
+// Strict reports whether decryption trusts version bytes, which is the case
+// when DecryptFormats is set.
+func (p *Policy) Strict() bool {
+	return p != nil && len(p.DecryptFormats) > 0
+}
+
+// AllowsKey returns a *PolicyError if a key's fingerprint has been revoked.
+func (p *Policy) AllowsKey(fingerprint string) error {
+	if p == nil {
+		return nil
+	}
+	for _, revoked := range p.RevokedFingerprints {
+		if strings.EqualFold(revoked, fingerprint) {
+			return &PolicyError{"revoked_fingerprints",
+				fmt.Sprintf("key %s has been revoked", fingerprint)}
+		}
+	}
+	return nil
+}
+
+// AllowsWorkbenchKey returns a *PolicyError if a Workbench key of the given
+// length in bytes and estimated entropy in bits is too weak.
+func (p *Policy) AllowsWorkbenchKey(length int, entropy float64) error {
+	if p == nil {
+		return nil
+	}
+	if length < p.MinWorkbenchKeyLength {
+		return &PolicyError{"min_workbench_key_length",
+			fmt.Sprintf("Workbench key is %d bytes, but must be at least %d",
+				length, p.MinWorkbenchKeyLength)}
+	}
+	if entropy < p.MinWorkbenchKeyEntropy {
+		return &PolicyError{"min_workbench_key_entropy",
+			fmt.Sprintf("Workbench key has an estimated %.0f bits of entropy, but must have at least %.0f",
+				entropy, p.MinWorkbenchKeyEntropy)}
+	}
+	return nil
+}
+
+// checkEncrypt applies the current Policy to encryption with k using the given
+// algorithm.
+func (k *Key) checkEncrypt(algorithm string) error {
+	p := CurrentPolicy()
+	if p == nil {
+		return nil
+	}
+	if err := p.AllowsKey(k.Fingerprint()); err != nil {
+		return err
+	}
+	return p.AllowsEncryption(algorithm)
+}
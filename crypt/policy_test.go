@@ -0,0 +1,182 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestLoadPolicy(c *check.C) {
+	p, err := LoadPolicy(strings.NewReader(`{
+  "encrypt_algorithms": ["aes-256-gcm", "aes-128-cbc"],
+  "decrypt_formats": ["v2"],
+  "min_workbench_key_length": 36,
+  "min_workbench_key_entropy": 100,
+  "revoked_fingerprints": ["BFA25145"]
+}`))
+	c.Assert(err, check.IsNil)
+	c.Check(p, check.DeepEquals, &Policy{
+		EncryptAlgorithms:      []string{AlgorithmAESGCM, AlgorithmAESCBC},
+		DecryptFormats:         []string{FormatV2},
+		MinWorkbenchKeyLength:  36,
+		MinWorkbenchKeyEntropy: 100,
+		RevokedFingerprints:    []string{"BFA25145"},
+	})
+	c.Check(p.Strict(), check.Equals, true)
+
+	_, err = LoadPolicy(strings.NewReader(`{"encrypt_algorithms": ["rot13"]}`))
+	c.Check(err, check.ErrorMatches, `invalid policy: unknown algorithm "rot13"`)
+	_, err = LoadPolicy(strings.NewReader(`{"decrypt_formats": ["v3"]}`))
+	c.Check(err, check.ErrorMatches, `invalid policy: unknown cipher text format "v3"`)
+	_, err = LoadPolicy(strings.NewReader(`{"revoked": []}`))
+	c.Check(err, check.ErrorMatches, `invalid policy: .*unknown field "revoked".*`)
+	c.Check(SetPolicy(&Policy{MinWorkbenchKeyLength: -1}), check.NotNil)
+	c.Check(CurrentPolicy(), check.IsNil)
+
+	// A nil policy allows everything.
+	var none *Policy
+	c.Check(none.AllowsEncryption(AlgorithmSecretbox), check.IsNil)
+	c.Check(none.AllowsDecryption(FormatLegacy), check.IsNil)
+	c.Check(none.AllowsKey("BFA25145"), check.IsNil)
+	c.Check(none.AllowsWorkbenchKey(0, 0), check.IsNil)
+}
+
+func (s *KeySuite) TestPolicyIntersect(c *check.C) {
+	system := &Policy{
+		EncryptAlgorithms:     []string{AlgorithmAESGCM, AlgorithmAESCBC},
+		MinWorkbenchKeyLength: 36,
+		RevokedFingerprints:   []string{"BFA25145"},
+	}
+	user := &Policy{
+		EncryptAlgorithms:      []string{AlgorithmSecretbox, AlgorithmAESGCM},
+		DecryptFormats:         []string{FormatV2},
+		MinWorkbenchKeyLength:  32,
+		MinWorkbenchKeyEntropy: 100,
+		RevokedFingerprints:    []string{"ABCDEF01"},
+	}
+	p, err := system.Intersect(user)
+	c.Assert(err, check.IsNil)
+	c.Check(p, check.DeepEquals, &Policy{
+		EncryptAlgorithms:      []string{AlgorithmAESGCM},
+		DecryptFormats:         []string{FormatV2},
+		MinWorkbenchKeyLength:  36,
+		MinWorkbenchKeyEntropy: 100,
+		RevokedFingerprints:    []string{"BFA25145", "ABCDEF01"},
+	})
+	c.Check(system.RevokedFingerprints, check.HasLen, 1)
+
+	p, err = (*Policy)(nil).Intersect(user)
+	c.Check(err, check.IsNil)
+	c.Check(p, check.Equals, user)
+	p, err = system.Intersect(nil)
+	c.Check(err, check.IsNil)
+	c.Check(p, check.Equals, system)
+
+	_, err = system.Intersect(&Policy{EncryptAlgorithms: []string{AlgorithmSecretbox}})
+	c.Check(err, check.ErrorMatches, `invalid policy: no encryption algorithms are allowed by both policies`)
+}
+
+func (s *KeySuite) TestPolicyEncryption(c *check.C) {
+	defer SetPolicy(nil)
+	key, _ := NewKey()
+
+	c.Check(EncryptsSecretbox(), check.Equals, !FIPSEnabled())
+
+	// Without secretbox, encryption uses AES, as in FIPS mode.
+	c.Assert(SetPolicy(&Policy{EncryptAlgorithms: []string{AlgorithmAESGCM}}), check.IsNil)
+	c.Check(EncryptsSecretbox(), check.Equals, false)
+	cipher, err := key.Encrypt("some secret")
+	c.Assert(err, check.IsNil)
+	buf, _ := base64.StdEncoding.DecodeString(cipher)
+	c.Check(buf[0], check.Equals, byte(2))
+	_, err = key.encryptVersioned("some secret")
+	c.Check(errors.Is(err, ErrPolicy), check.Equals, true)
+	// The same applies to the AEAD adapters.
+	_, err = key.AEAD()
+	c.Check(err, check.IsNil)
+	_, err = key.SecretboxAEAD()
+	c.Check(errors.Is(err, ErrPolicy) || err == ErrFIPS, check.Equals, true)
+
+	c.Assert(SetPolicy(&Policy{EncryptAlgorithms: []string{AlgorithmAESCBC}}), check.IsNil)
+	_, err = key.Encrypt("some secret")
+	var perr *PolicyError
+	c.Assert(errors.As(err, &perr), check.Equals, true)
+	c.Check(perr.Rule, check.Equals, "encrypt_algorithms")
+	c.Check(err, check.ErrorMatches,
+		`Forbidden by crypto policy \(encrypt_algorithms\): aes-256-gcm encryption is not allowed`)
+	_, err = key.EncryptFIPS("some secret")
+	c.Check(errors.Is(err, ErrPolicy), check.Equals, true)
+	_, err = key.AEAD()
+	c.Check(errors.Is(err, ErrPolicy), check.Equals, true)
+}
+
+func (s *KeySuite) TestPolicyDecryption(c *check.C) {
+	defer SetPolicy(nil)
+	key, _ := NewKey()
+	aes, err := key.EncryptFIPS("some secret")
+	c.Assert(err, check.IsNil)
+	var legacy, v1 string
+	if !FIPSEnabled() {
+		legacy, err = key.Encrypt("some secret")
+		c.Assert(err, check.IsNil)
+		v1, err = key.encryptVersioned("some secret")
+		c.Assert(err, check.IsNil)
+	}
+
+	c.Assert(SetPolicy(&Policy{DecryptFormats: []string{FormatV2}}), check.IsNil)
+	text, err := key.Decrypt(aes)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "some secret")
+	if v1 != "" {
+		_, err = key.Decrypt(v1)
+		c.Check(err, check.ErrorMatches, `.*\(decrypt_formats\): v1 cipher text cannot be decrypted`)
+	}
+
+	// Strict decryption trusts the version byte.
+	c.Assert(SetPolicy(&Policy{DecryptFormats: []string{FormatLegacy, FormatV1}}), check.IsNil)
+	if legacy != "" {
+		buf, _ := base64.StdEncoding.DecodeString(legacy)
+		text, err = key.Decrypt(legacy)
+		switch buf[0] {
+		case 1:
+			c.Check(err, check.Equals, ErrFailedToDecrypt)
+		case 2:
+			c.Check(errors.Is(err, ErrPolicy), check.Equals, true)
+		default:
+			c.Check(err, check.IsNil)
+			c.Check(text, check.Equals, "some secret")
+		}
+		text, err = key.Decrypt(v1)
+		c.Check(err, check.IsNil)
+		c.Check(text, check.Equals, "some secret")
+	}
+	_, err = key.Decrypt(aes)
+	c.Check(err, check.ErrorMatches, `.*v2 cipher text cannot be decrypted`)
+}
+
+func (s *KeySuite) TestPolicyRevokedKey(c *check.C) {
+	defer SetPolicy(nil)
+	key, _ := NewKey()
+	cipher, err := key.EncryptFIPS("some secret")
+	c.Assert(err, check.IsNil)
+
+	revoked := strings.ToUpper(key.Fingerprint())
+	c.Assert(SetPolicy(&Policy{RevokedFingerprints: []string{revoked}}), check.IsNil)
+	_, err = NewKeyFromBytes([]byte(key.HexString()))
+	c.Check(err, check.ErrorMatches, `.*\(revoked_fingerprints\): key [0-9a-f]+ has been revoked`)
+	_, err = key.Encrypt("some secret")
+	c.Check(errors.Is(err, ErrPolicy), check.Equals, true)
+	_, err = key.Decrypt(cipher)
+	c.Check(errors.Is(err, ErrPolicy), check.Equals, true)
+	_, err = key.AEAD()
+	c.Check(errors.Is(err, ErrPolicy), check.Equals, true)
+
+	other, _ := NewKey()
+	_, err = NewKeyFromBytes([]byte(other.HexString()))
+	c.Check(err, check.IsNil)
+}
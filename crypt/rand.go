@@ -0,0 +1,52 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"io"
+)
+
+// KeyWithRand is a Key that reads nonces from a custom source of randomness
+// instead of crypto/rand. See Key.WithRand().
+type KeyWithRand struct {
+	*Key
+	rand io.Reader
+}
+
+// WithRand returns a copy of the key that reads nonces from r when encrypting.
+//
+// This is intended for tests that need stable cipher texts, and r should
+// always be crypto/rand.Reader otherwise. Reusing a nonce with the same key
+// destroys the security of both AES-GCM and Secretbox. The crypttest package
+// provides a suitable deterministic reader.
+func (k *Key) WithRand(r io.Reader) *KeyWithRand {
+	return &KeyWithRand{k, r}
+}
+
+// Encrypt is the equivalent of Key.Encrypt().
+func (k *KeyWithRand) Encrypt(s string) (string, error) {
+	return k.encryptBytes(k.rand, []byte(s))
+}
+
+// EncryptBytes is the equivalent of Key.EncryptBytes().
+func (k *KeyWithRand) EncryptBytes(bytes []byte) (string, error) {
+	return k.encryptBytes(k.rand, bytes)
+}
+
+// EncryptFIPS is the equivalent of Key.EncryptFIPS(), except that it returns an
+// error if the reader does.
+func (k *KeyWithRand) EncryptFIPS(s string) (string, error) {
+	return k.encryptBytesFIPS(k.rand, []byte(s))
+}
+
+// EncryptBytesFIPS is the equivalent of Key.EncryptBytesFIPS(), except that it
+// returns an error if the reader does.
+func (k *KeyWithRand) EncryptBytesFIPS(bytes []byte) (string, error) {
+	return k.encryptBytesFIPS(k.rand, bytes)
+}
+
+// FIPSCipher is the equivalent of Key.FIPSCipher().
+func (k *KeyWithRand) FIPSCipher() Cipher {
+	return fipsCipher{k.Key, k.rand}
+}
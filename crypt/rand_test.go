@@ -0,0 +1,45 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"bytes"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestWithRand(c *check.C) {
+	key, _ := NewKeyFromBytes([]byte(sampleKey))
+	zeros := func() *KeyWithRand {
+		return key.WithRand(bytes.NewReader(make([]byte, 64)))
+	}
+
+	// The same nonces yield the same cipher texts.
+	c1, err := zeros().EncryptFIPS("some secret")
+	c.Check(err, check.IsNil)
+	c2, err := zeros().EncryptBytesFIPS([]byte("some secret"))
+	c.Check(err, check.IsNil)
+	c.Check(c1, check.Equals, c2)
+	c2, err = zeros().FIPSCipher().Encrypt("some secret")
+	c.Check(err, check.IsNil)
+	c.Check(c1, check.Equals, c2)
+	text, err := zeros().Decrypt(c1)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "some secret")
+
+	c1, err = zeros().Encrypt("some secret")
+	c.Check(err, check.IsNil)
+	c2, err = zeros().EncryptBytes([]byte("some secret"))
+	c.Check(err, check.IsNil)
+	c.Check(c1, check.Equals, c2)
+	text, err = key.Decrypt(c1)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "some secret")
+
+	// Errors from the reader are returned.
+	_, err = key.WithRand(&errReader{}).EncryptFIPS("some secret")
+	c.Check(err, check.ErrorMatches, `cannot read`)
+	_, err = key.WithRand(&errReader{}).Encrypt("some secret")
+	c.Check(err, check.ErrorMatches, `cannot read`)
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// String returns a redacted description of the key that includes a prefix of
+// its fingerprint, so that keys are never accidentally written to logs.
+func (k Key) String() string {
+	return fmt.Sprintf("crypt.Key(%s)", k.Fingerprint()[:16])
+}
+
+// Format implements fmt.Formatter. All verbs print the output of String().
+func (k Key) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(k.String()))
+}
+
+// LogValue implements slog.LogValuer. Keys are logged in redacted form.
+func (k Key) LogValue() slog.Value {
+	return slog.StringValue(k.String())
+}
+
+// MarshalText implements encoding.TextMarshaler, using the same format as
+// HexString().
+func (k Key) MarshalText() ([]byte, error) {
+	return []byte(k.HexString()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting any format
+// accepted by NewKeyFromBytes().
+func (k *Key) UnmarshalText(text []byte) error {
+	key, err := NewKeyFromBytes(text)
+	if err != nil {
+		return err
+	}
+	*k = *key
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the key as a string in the
+// same format as HexString().
+func (k Key) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.HexString())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting a string in any format
+// accepted by NewKeyFromBytes().
+func (k *Key) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return k.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestRedaction(c *check.C) {
+	key, _ := NewKeyFromBytes([]byte(sampleKey))
+	redacted := "crypt.Key(" + sampleHash[:16] + ")"
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%x", "%q", "%d"} {
+		c.Check(fmt.Sprintf(verb, key), check.Equals, redacted)
+		c.Check(fmt.Sprintf(verb, *key), check.Equals, redacted)
+	}
+	c.Check(fmt.Sprint(struct{ Key *Key }{key}), check.Equals, "{"+redacted+"}")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("loaded", "key", key)
+	c.Check(buf.String(), check.Matches, `(?s).*"key":"`+regexp.QuoteMeta(redacted)+`".*`)
+	c.Check(strings.Contains(buf.String(), sampleKey[:16]), check.Equals, false)
+}
+
+func (s *KeySuite) TestTextMarshaling(c *check.C) {
+	key, _ := NewKeyFromBytes([]byte(sampleKey))
+
+	text, err := key.MarshalText()
+	c.Check(err, check.IsNil)
+	c.Check(string(text), check.Equals, sampleKey)
+
+	var k Key
+	c.Check(k.UnmarshalText([]byte(sampleKeyB64)), check.IsNil)
+	c.Check(&k, check.DeepEquals, key)
+	c.Check(k.UnmarshalText([]byte("too short")), check.Equals, ErrInvalidKeyLength)
+
+	type config struct {
+		Name string `json:"name"`
+		Key  Key    `json:"key"`
+	}
+	out, err := json.Marshal(config{"test", *key})
+	c.Check(err, check.IsNil)
+	c.Check(string(out), check.Equals, `{"name":"test","key":"`+sampleKey+`"}`)
+
+	var cfg config
+	c.Check(json.Unmarshal(out, &cfg), check.IsNil)
+	c.Check(&cfg.Key, check.DeepEquals, key)
+	c.Check(json.Unmarshal([]byte(`{"key": 42}`), &cfg), check.Not(check.IsNil))
+	c.Check(json.Unmarshal([]byte(`{"key": "too short"}`), &cfg), check.Equals,
+		ErrInvalidKeyLength)
+}
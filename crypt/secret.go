@@ -0,0 +1,252 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// ErrNoSecretCipher reports an attempt to decrypt or encrypt a Secret when no
+// Cipher is available.
+var ErrNoSecretCipher = errors.New("No key is available for this secret")
+
+var (
+	secretCipherMu sync.RWMutex
+	secretCipher   Cipher
+)
+
+// BindSecretCipher sets the Cipher used by every Secret that is not bound to
+// one explicitly, such as those created by unmarshaling a configuration file.
+// Passing nil removes the binding.
+func BindSecretCipher(c Cipher) {
+	secretCipherMu.Lock()
+	defer secretCipherMu.Unlock()
+	secretCipher = c
+}
+
+func boundSecretCipher() Cipher {
+	secretCipherMu.RLock()
+	defer secretCipherMu.RUnlock()
+	return secretCipher
+}
+
+type secretCipherKey struct{}
+
+// WithSecretCipher returns a copy of ctx that carries a Cipher for use by
+// Secret.Resolve() and ResolveSecrets().
+func WithSecretCipher(ctx context.Context, c Cipher) context.Context {
+	return context.WithValue(ctx, secretCipherKey{}, c)
+}
+
+// SecretCipherFromContext returns the Cipher carried by ctx, if any.
+func SecretCipherFromContext(ctx context.Context) (Cipher, bool) {
+	c, ok := ctx.Value(secretCipherKey{}).(Cipher)
+	return c, ok && c != nil
+}
+
+// Secret is a configuration value that is stored as cipher text and
+// transparently decrypted when it is unmarshaled from text or JSON. It never
+// prints its plain text; use Reveal() to access it.
+//
+// Unmarshaling decrypts the value immediately using the Cipher set with
+// BindSecretCipher(), if there is one. Otherwise the cipher text is kept until
+// the secret is resolved with Resolve() or ResolveSecrets().
+//
+// Marshaling a Secret encrypts it with the Cipher it is bound to, keeping the
+// original cipher text if it is still valid (see EncryptStable). Secrets that
+// have not been decrypted yet marshal to their original cipher text.
+//
+// An empty value, JSON null, or a missing one leaves the Secret unset. Unset
+// secrets reveal an empty string without a Cipher, and marshal to an empty
+// value.
+type Secret struct {
+	plaintext  string
+	ciphertext string
+	// Whether the plain text is known.
+	resolved bool
+	cipher   Cipher
+}
+
+// NewSecret returns a Secret with the given plain text.
+func NewSecret(plaintext string) Secret {
+	return Secret{plaintext: plaintext, resolved: true}
+}
+
+// Bind sets the Cipher used to decrypt and encrypt this secret, overriding the
+// one set with BindSecretCipher().
+func (s *Secret) Bind(c Cipher) {
+	s.cipher = c
+}
+
+func (s *Secret) boundCipher() Cipher {
+	if s.cipher != nil {
+		return s.cipher
+	}
+	return boundSecretCipher()
+}
+
+// Reveal returns the plain text of the secret, decrypting it if necessary.
+func (s *Secret) Reveal() (string, error) {
+	if s.resolved || s.unset() {
+		return s.plaintext, nil
+	}
+	c := s.boundCipher()
+	if c == nil {
+		return "", ErrNoSecretCipher
+	}
+	if err := s.decrypt(c); err != nil {
+		return "", err
+	}
+	return s.plaintext, nil
+}
+
+// Resolve decrypts the secret using the Cipher carried by ctx, which is then
+// bound to the secret. If ctx carries no Cipher, the one the secret is already
+// bound to is used instead. Secrets that are already decrypted are left
+// unchanged.
+func (s *Secret) Resolve(ctx context.Context) error {
+	if s.resolved || s.unset() {
+		return nil
+	}
+	c, ok := SecretCipherFromContext(ctx)
+	if !ok {
+		c = s.boundCipher()
+	}
+	if c == nil {
+		return ErrNoSecretCipher
+	}
+	s.cipher = c
+	return s.decrypt(c)
+}
+
+// unset reports whether the secret has neither plain text nor cipher text.
+func (s *Secret) unset() bool {
+	return !s.resolved && s.ciphertext == ""
+}
+
+func (s *Secret) decrypt(c Cipher) error {
+	plaintext, err := c.Decrypt(s.ciphertext)
+	if err != nil {
+		return err
+	}
+	s.plaintext = plaintext
+	s.resolved = true
+	return nil
+}
+
+// String returns a fixed placeholder. It never reveals the plain text.
+func (s Secret) String() string {
+	return "[REDACTED]"
+}
+
+// Format implements fmt.Formatter. All verbs print the output of String().
+func (s Secret) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(s.String()))
+}
+
+// LogValue implements slog.LogValuer. Secrets are always logged redacted.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// MarshalText implements encoding.TextMarshaler, producing cipher text.
+func (s Secret) MarshalText() ([]byte, error) {
+	if !s.resolved {
+		return []byte(s.ciphertext), nil
+	}
+	c := s.boundCipher()
+	if c == nil {
+		return nil, ErrNoSecretCipher
+	}
+	// Keep the original cipher text if it is still valid, so that
+	// round trips are stable.
+	out, err := EncryptStable(c, s.plaintext, s.ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting cipher text.
+func (s *Secret) UnmarshalText(text []byte) error {
+	*s = Secret{ciphertext: string(text), cipher: s.cipher}
+	if s.unset() {
+		return nil
+	}
+	if c := s.boundCipher(); c != nil {
+		s.cipher = c
+		return s.decrypt(c)
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding cipher text as a string.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	text, err := s.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting cipher text as a
+// string, or null.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = Secret{cipher: s.cipher}
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return s.UnmarshalText([]byte(text))
+}
+
+// ResolveSecrets calls Resolve() on every Secret reachable from v through
+// pointers, struct fields, slices, and arrays, using the Cipher carried by
+// ctx. It is intended for use with configuration structs after unmarshaling.
+func ResolveSecrets(ctx context.Context, v any) error {
+	return resolveValue(ctx, reflect.ValueOf(v))
+}
+
+var secretType = reflect.TypeOf(Secret{})
+
+func resolveValue(ctx context.Context, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return resolveValue(ctx, v.Elem())
+	case reflect.Struct:
+		if v.Type() == secretType {
+			if !v.CanAddr() {
+				return nil
+			}
+			return v.Addr().Interface().(*Secret).Resolve(ctx)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := resolveValue(ctx, v.Field(i)); err != nil {
+				return fmt.Errorf("%s: %w", v.Type().Field(i).Name, err)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveValue(ctx, v.Index(i)); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/check.v1"
+)
+
+type secretConfig struct {
+	Name     string   `json:"name"`
+	Password Secret   `json:"password"`
+	Tokens   []Secret `json:"tokens"`
+	Nested   *struct {
+		Secret Secret `json:"secret"`
+	} `json:"nested"`
+}
+
+func (s *KeySuite) TestSecret(c *check.C) {
+	key, _ := NewKey()
+	BindSecretCipher(key)
+	defer BindSecretCipher(nil)
+
+	cipher, _ := key.Encrypt("hunter2")
+	var cfg secretConfig
+	err := json.Unmarshal([]byte(`{"name": "db", "password": "`+cipher+`"}`), &cfg)
+	c.Assert(err, check.IsNil)
+	text, err := cfg.Password.Reveal()
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+
+	// The plain text is never printed.
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q"} {
+		c.Check(fmt.Sprintf(verb, cfg.Password), check.Equals, "[REDACTED]")
+	}
+	c.Check(cfg.Password.String(), check.Equals, "[REDACTED]")
+
+	// Marshaling keeps the original cipher text.
+	out, err := json.Marshal(cfg)
+	c.Assert(err, check.IsNil)
+	c.Check(string(out), check.Matches, `.*"password":"`+regexp.QuoteMeta(cipher)+`".*`)
+	var roundtrip secretConfig
+	c.Assert(json.Unmarshal(out, &roundtrip), check.IsNil)
+	text, err = roundtrip.Password.Reveal()
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+
+	// Empty and null values are unset, and stay that way.
+	for _, value := range []string{`""`, `null`} {
+		cfg = secretConfig{}
+		err = json.Unmarshal([]byte(`{"password": `+value+`}`), &cfg)
+		c.Assert(err, check.IsNil, check.Commentf(value))
+		text, err = cfg.Password.Reveal()
+		c.Check(err, check.IsNil)
+		c.Check(text, check.Equals, "")
+		out, err = json.Marshal(cfg.Password)
+		c.Check(err, check.IsNil)
+		c.Check(string(out), check.Equals, `""`)
+	}
+
+	// Values that cannot be decrypted are an error.
+	err = json.Unmarshal([]byte(`{"password": "bm90IGEgc2VjcmV0"}`), &cfg)
+	c.Check(err, check.Not(check.IsNil))
+	err = json.Unmarshal([]byte(`{"password": 42}`), &cfg)
+	c.Check(err, check.Not(check.IsNil))
+
+	// New secrets are encrypted using the FIPS cipher, if it is bound.
+	BindSecretCipher(key.FIPSCipher())
+	out, err = NewSecret("hunter2").MarshalText()
+	c.Check(err, check.IsNil)
+	buf, _ := base64.StdEncoding.DecodeString(string(out))
+	c.Check(buf[0], check.Equals, byte(2))
+	text, err = key.Decrypt(string(out))
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+}
+
+func (s *KeySuite) TestSecretContext(c *check.C) {
+	key, _ := NewKey()
+	other, _ := NewKey()
+	c1, _ := key.Encrypt("hunter2")
+	c2, _ := key.Encrypt("token")
+	c3, _ := key.Encrypt("nested")
+
+	// Without a bound cipher, decryption is deferred.
+	var cfg secretConfig
+	err := json.Unmarshal([]byte(`{"password": "`+c1+`", "tokens": ["`+c2+
+		`"], "nested": {"secret": "`+c3+`"}}`), &cfg)
+	c.Assert(err, check.IsNil)
+	_, err = cfg.Password.Reveal()
+	c.Check(err, check.Equals, ErrNoSecretCipher)
+	_, err = NewSecret("hunter2").MarshalText()
+	c.Check(err, check.Equals, ErrNoSecretCipher)
+
+	// Unresolved secrets marshal to their original cipher text.
+	out, err := cfg.Password.MarshalText()
+	c.Check(err, check.IsNil)
+	c.Check(string(out), check.Equals, c1)
+
+	c.Check(ResolveSecrets(context.Background(), &cfg), check.ErrorMatches,
+		`Password: No key is available.*`)
+	err = ResolveSecrets(WithSecretCipher(context.Background(), other), &cfg)
+	c.Check(err, check.ErrorMatches, `Password: Decryption failed`)
+
+	ctx := WithSecretCipher(context.Background(), key)
+	c.Assert(ResolveSecrets(ctx, &cfg), check.IsNil)
+	for secret, want := range map[*Secret]string{
+		&cfg.Password:      "hunter2",
+		&cfg.Tokens[0]:     "token",
+		&cfg.Nested.Secret: "nested",
+	} {
+		text, err := secret.Reveal()
+		c.Check(err, check.IsNil)
+		c.Check(text, check.Equals, want)
+	}
+
+	// Resolved secrets are bound to the context's cipher.
+	out, err = cfg.Password.MarshalText()
+	c.Check(err, check.IsNil)
+	text, err := key.Decrypt(string(out))
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+
+	// Unset secrets need no cipher.
+	var unset secretConfig
+	c.Assert(json.Unmarshal([]byte(`{"password": ""}`), &unset), check.IsNil)
+	c.Check(ResolveSecrets(context.Background(), &unset), check.IsNil)
+	text, err = unset.Password.Reveal()
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "")
+
+	// An explicitly bound cipher is used when unmarshaling, too.
+	var secret Secret
+	secret.Bind(key)
+	c.Check(secret.UnmarshalText([]byte(c1)), check.IsNil)
+	text, err = secret.Reveal()
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+}
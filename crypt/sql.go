@@ -0,0 +1,34 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// Scan implements sql.Scanner, accepting cipher text stored in a string or
+// byte column. Use sql.Null[Secret] for nullable columns.
+func (s *Secret) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return s.UnmarshalText([]byte(v))
+	case []byte:
+		return s.UnmarshalText(v)
+	case nil:
+		return errors.New("cannot scan NULL into a Secret; use sql.Null[crypt.Secret]")
+	}
+	return fmt.Errorf("cannot scan %T into a Secret", src)
+}
+
+// Value implements driver.Valuer, producing cipher text in the same way as
+// MarshalText().
+func (s Secret) Value() (driver.Value, error) {
+	text, err := s.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"database/sql"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestSecretSQL(c *check.C) {
+	key, _ := NewKey()
+	cipher, _ := key.Encrypt("hunter2")
+
+	var secret Secret
+	secret.Bind(key)
+	c.Check(secret.Scan(cipher), check.IsNil)
+	text, err := secret.Reveal()
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+	c.Check(secret.Scan([]byte(cipher)), check.IsNil)
+	text, err = secret.Reveal()
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+
+	c.Check(secret.Scan(nil), check.ErrorMatches, `cannot scan NULL.*`)
+	c.Check(secret.Scan(42), check.ErrorMatches, `cannot scan int into a Secret`)
+
+	var null sql.Null[Secret]
+	c.Check(null.Scan(nil), check.IsNil)
+	c.Check(null.Valid, check.Equals, false)
+
+	value, err := secret.Value()
+	c.Check(err, check.IsNil)
+	text, err = key.Decrypt(value.(string))
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+
+	_, err = NewSecret("hunter2").Value()
+	c.Check(err, check.Equals, ErrNoSecretCipher)
+}
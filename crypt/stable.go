@@ -0,0 +1,85 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// EncryptStable is like c.Encrypt(plaintext), except that if one of the
+// previous cipher texts already decrypts to the same plain text under the same
+// key, it is returned unchanged. This avoids churn when the same secret is
+// encrypted repeatedly, e.g. in configuration management.
+//
+// A previous cipher text is only kept if it uses the format that c would use
+// now, so that (for example) secretbox cipher texts are replaced when FIPS mode
+// is enabled. For a Keyring, it must decrypt under the active key.
+func EncryptStable(c Cipher, plaintext string, previous ...string) (string, error) {
+	// Always encrypt, so that anything that would make encryption fail
+	// (such as the current Policy) still does.
+	fresh, err := c.Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	if r, ok := c.(*Keyring); ok {
+		c = r.Active()
+	}
+	for _, p := range previous {
+		if p != "" && decryptsTo(c, p, plaintext) {
+			return p, nil
+		}
+	}
+	return fresh, nil
+}
+
+// currentDecrypter is implemented by ciphers with more than one cipher text
+// format. It decrypts only cipher text in the format that Encrypt() would
+// currently produce.
+type currentDecrypter interface {
+	decryptCurrent(s string) ([]byte, error)
+}
+
+func decryptsTo(c Cipher, ciphertext, plaintext string) bool {
+	var text []byte
+	if d, ok := c.(currentDecrypter); ok {
+		var err error
+		if text, err = d.decryptCurrent(ciphertext); err != nil {
+			return false
+		}
+	} else {
+		s, err := c.Decrypt(ciphertext)
+		if err != nil {
+			return false
+		}
+		text = []byte(s)
+	}
+	return subtle.ConstantTimeCompare(text, []byte(plaintext)) == 1
+}
+
+func (k *Key) decryptCurrent(s string) ([]byte, error) {
+	if !EncryptsSecretbox() {
+		return k.decryptCurrentAES(s)
+	}
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return k.decryptSecretbox(buf)
+}
+
+func (f fipsCipher) decryptCurrent(s string) ([]byte, error) {
+	return f.decryptCurrentAES(s)
+}
+
+func (k *Key) decryptCurrentAES(s string) ([]byte, error) {
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < 1 || buf[0] != 2 {
+		return nil, ErrFailedToDecrypt
+	}
+	return k.decryptAES(buf)
+}
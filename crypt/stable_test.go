@@ -0,0 +1,103 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypt
+
+import (
+	"errors"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *KeySuite) TestEncryptStable(c *check.C) {
+	key, _ := NewKey()
+	other, _ := NewKey()
+	previous, err := key.Encrypt("hunter2")
+	c.Assert(err, check.IsNil)
+	elsewhere, err := other.Encrypt("hunter2")
+	c.Assert(err, check.IsNil)
+
+	out, err := EncryptStable(key, "hunter2", previous)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Equals, previous)
+	out, err = EncryptStable(key, "hunter2", "", "garbage", elsewhere, previous)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Equals, previous)
+
+	for _, p := range []string{"", "garbage", elsewhere} {
+		out, err = EncryptStable(key, "hunter2", p)
+		c.Check(err, check.IsNil)
+		c.Check(out, check.Not(check.Equals), p)
+		text, err := key.Decrypt(out)
+		c.Check(err, check.IsNil)
+		c.Check(text, check.Equals, "hunter2")
+	}
+	out, err = EncryptStable(key, "hunter3", previous)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Not(check.Equals), previous)
+
+	// Anything that makes encryption fail still does.
+	defer SetPolicy(nil)
+	c.Assert(SetPolicy(&Policy{RevokedFingerprints: []string{key.Fingerprint()}}), check.IsNil)
+	_, err = EncryptStable(key, "hunter2", previous)
+	c.Check(errors.Is(err, ErrPolicy), check.Equals, true)
+}
+
+func (s *KeySuite) TestEncryptStableFormat(c *check.C) {
+	key, _ := NewKey()
+	fips := key.FIPSCipher()
+	aes, err := fips.Encrypt("hunter2")
+	c.Assert(err, check.IsNil)
+
+	out, err := EncryptStable(fips, "hunter2", aes)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Equals, aes)
+
+	if FIPSEnabled() {
+		// The default mode also uses AES in FIPS mode.
+		out, err = EncryptStable(key, "hunter2", aes)
+		c.Check(err, check.IsNil)
+		c.Check(out, check.Equals, aes)
+		return
+	}
+	// Cipher texts in another format are replaced.
+	secretbox, err := key.Encrypt("hunter2")
+	c.Assert(err, check.IsNil)
+	out, err = EncryptStable(fips, "hunter2", secretbox)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Not(check.Equals), secretbox)
+	out, err = EncryptStable(key, "hunter2", aes)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Not(check.Equals), aes)
+
+	// Including when the policy changes the default.
+	defer SetPolicy(nil)
+	c.Assert(SetPolicy(&Policy{EncryptAlgorithms: []string{AlgorithmAESGCM}}), check.IsNil)
+	out, err = EncryptStable(key, "hunter2", secretbox)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Not(check.Equals), secretbox)
+	out, err = EncryptStable(key, "hunter2", aes)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Equals, aes)
+}
+
+func (s *KeySuite) TestEncryptStableKeyring(c *check.C) {
+	old, _ := NewKey()
+	ring := NewKeyring(old, KeyringOptions{})
+	previous, err := ring.Encrypt("hunter2")
+	c.Assert(err, check.IsNil)
+	out, err := EncryptStable(ring, "hunter2", previous)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Equals, previous)
+
+	// After rotation, the old key can still decrypt, but the value is
+	// re-encrypted with the new one.
+	new, _ := NewKey()
+	ring.Rotate(new)
+	out, err = EncryptStable(ring, "hunter2", previous)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Not(check.Equals), previous)
+	text, err := new.Decrypt(out)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package crypttest provides deterministic keys, a deterministic source of
+// nonces and IVs, and assertions for use in tests of code that stores secrets
+// in the formats used by Posit's products.
+//
+// None of this is secure. Cipher texts produced with these helpers can be
+// decrypted by anyone who knows the seed.
+package crypttest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/workbench"
+)
+
+// TB is the part of testing.TB used to report failures. It is implemented by
+// *testing.T and *testing.B, and by *check.C from gopkg.in/check.v1. Helper()
+// is called if t has it.
+type TB interface {
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// expand fills buf with a SHA-256-based stream derived from a label and seed.
+func expand(buf []byte, label, seed string) {
+	var counter [8]byte
+	for i := uint64(0); len(buf) > 0; i++ {
+		binary.BigEndian.PutUint64(counter[:], i)
+		h := sha256.New()
+		h.Write([]byte(label))
+		h.Write([]byte{0})
+		h.Write([]byte(seed))
+		h.Write(counter[:])
+		n := copy(buf, h.Sum(nil))
+		buf = buf[n:]
+	}
+}
+
+// NewKey returns a crypt.Key derived from the given seed. The same seed always
+// yields the same key.
+func NewKey(seed string) *crypt.Key {
+	var key crypt.Key
+	expand(key[:], "rskey crypt.Key", seed)
+	return &key
+}
+
+// WorkbenchKeyData returns the contents of a Workbench secure-cookie-key file
+// derived from the given seed. Like real keys, it is a UUID literal with a
+// trailing newline.
+func WorkbenchKeyData(seed string) []byte {
+	b := make([]byte, 16)
+	expand(b, "rskey workbench.Key", seed)
+	// Set the version 4 and variant bits.
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return []byte(fmt.Sprintf("%x-%x-%x-%x-%x\n", b[0:4], b[4:6], b[6:8],
+		b[8:10], b[10:16]))
+}
+
+// NewWorkbenchKey returns a workbench.Key derived from the given seed. The same
+// seed always yields the same key.
+func NewWorkbenchKey(seed string) *workbench.Key {
+	// This cannot fail, since the data is long enough.
+	key, _ := workbench.NewKeyFromBytes(WorkbenchKeyData(seed))
+	return key
+}
+
+// CorruptWorkbenchValue returns a Workbench cipher text that carries the
+// checksum of key, but does not decrypt, like a value damaged in a file.
+func CorruptWorkbenchValue(key *workbench.Key) string {
+	body := make([]byte, 48)
+	for i := 0; ; i++ {
+		expand(body, "rskey corrupt", fmt.Sprint(i))
+		s := key.Fingerprint() + base64.StdEncoding.EncodeToString(body) + key.Fingerprint()
+		if _, err := key.Decrypt(s); err != nil {
+			return s
+		}
+	}
+}
+
+// NewRand returns a deterministic source of nonces and IVs derived from the
+// given seed, for use with the WithRand() option of the key types. It fails
+// the test if it is not running in a test binary.
+func NewRand(t TB, seed string) io.Reader {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	if !testing.Testing() {
+		t.Fatalf("crypttest: NewRand can only be used in tests")
+	}
+	return &reader{seed: seed}
+}
+
+// reader is an endless deterministic stream.
+type reader struct {
+	seed string
+	// The stream is produced in blocks, indexed by count.
+	count uint64
+	buf   []byte
+}
+
+// Read implements io.Reader.
+func (r *reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.buf) == 0 {
+			r.buf = make([]byte, sha256.Size)
+			expand(r.buf, "rskey rand", fmt.Sprintf("%s/%d", r.seed, r.count))
+			r.count++
+		}
+		c := copy(p[n:], r.buf)
+		r.buf = r.buf[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// NewCipher returns a crypt.Cipher for a mode with a key derived from the given
+// seed, which produces the same cipher texts every time it is used in the same
+// sequence. The "default", "fips", and "workbench" modes are supported.
+func NewCipher(t TB, mode, seed string) crypt.Cipher {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	r := NewRand(t, seed)
+	switch mode {
+	case "default":
+		return NewKey(seed).WithRand(r)
+	case "fips":
+		return NewKey(seed).WithRand(r).FIPSCipher()
+	case "workbench":
+		return NewWorkbenchKey(seed).WithRand(r)
+	}
+	t.Fatalf("crypttest: unsupported mode %q", mode)
+	return nil
+}
+
+// Encrypt returns the cipher text of plaintext under the given Cipher, and
+// stops the test if encryption fails.
+func Encrypt(t TB, c crypt.Cipher, plaintext string) string {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	ciphertext, err := c.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("cannot encrypt in %s mode: %v", c.Mode(), err)
+	}
+	return ciphertext
+}
+
+// AssertDecrypts checks that a cipher text decrypts to the expected value
+// under the given Cipher, and marks the test as failed otherwise.
+func AssertDecrypts(t TB, c crypt.Cipher, ciphertext, want string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	got, err := c.Decrypt(ciphertext)
+	if err != nil {
+		t.Errorf("%q does not decrypt in %s mode: %v", ciphertext, c.Mode(), err)
+		return false
+	}
+	if got != want {
+		t.Errorf("%q decrypts to %q in %s mode, want %q", ciphertext, got,
+			c.Mode(), want)
+		return false
+	}
+	return true
+}
+
+// AssertDecryptsMode checks that a cipher text decrypts to the expected value
+// when the given key data is loaded using the mode's registered loader, and
+// marks the test as failed otherwise.
+func AssertDecryptsMode(t TB, mode string, key []byte, ciphertext, want string) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	c, err := crypt.LoadCipher(mode, bytes.NewReader(key))
+	if err != nil {
+		t.Errorf("cannot load %s key: %v", mode, err)
+		return false
+	}
+	return AssertDecrypts(t, c, ciphertext, want)
+}
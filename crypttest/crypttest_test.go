@@ -0,0 +1,93 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package crypttest
+
+import (
+	"fmt"
+	"testing"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	errors []string
+}
+
+// Errorf implements TB.
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf implements TB.
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+type CrypttestSuite struct{}
+
+func (s *CrypttestSuite) TestDeterministicKeys(c *check.C) {
+	c.Check(NewKey("a").Fingerprint(), check.Equals, NewKey("a").Fingerprint())
+	c.Check(NewKey("a").Fingerprint(), check.Not(check.Equals), NewKey("b").Fingerprint())
+	c.Check(string(WorkbenchKeyData("a")), check.Equals, "5a40aeab-ecba-4632-9499-0fc7fc300b78\n")
+	c.Check(NewWorkbenchKey("a").Fingerprint(), check.Not(check.Equals),
+		NewWorkbenchKey("b").Fingerprint())
+}
+
+func (s *CrypttestSuite) TestStableCiphertexts(c *check.C) {
+	golden := map[string]string{
+		"default":   "qtDc31ITUxFWuG/QuRfb0NA1eTc8DvSNEWtIjTI6zME8A5uNNuUsz+yPiblvn6w9yUlG",
+		"fips":      "AqrQ3N9SE1MRVrhv0HlR2J2JeO7DquyTVlzcID/HRC1gLCrYD36SHQ==",
+		"workbench": "B44A72CBqtDc31ITUxFWuG/QuRfb0AAAAAAAAAAAAAAAAAAAAAAaPfr0FAbvz5UBxxe/OltoB44A72CB",
+	}
+	if crypt.FIPSEnabled() {
+		// The default mode uses AES-256-GCM instead.
+		golden["default"] = golden["fips"]
+	}
+	for mode, want := range golden {
+		c1 := NewCipher(c, mode, "seed")
+		c2 := NewCipher(c, mode, "seed")
+		out1, err := c1.Encrypt("some secret")
+		c.Assert(err, check.IsNil)
+		out2, _ := c2.Encrypt("some secret")
+		c.Check(out2, check.Equals, out1, check.Commentf("%s: cipher texts differ", mode))
+		c.Check(out1, check.Equals, want, check.Commentf(mode))
+		// Later cipher texts in the sequence use fresh nonces.
+		out3, _ := c1.Encrypt("some secret")
+		c.Check(out3, check.Not(check.Equals), out1, check.Commentf("%s: nonce was reused", mode))
+		AssertDecrypts(c, c1, out1, "some secret")
+		AssertDecrypts(c, c1, out3, "some secret")
+	}
+}
+
+func (s *CrypttestSuite) TestAssertions(c *check.C) {
+	cipher := NewCipher(c, "default", "seed")
+	out, _ := cipher.Encrypt("some secret")
+
+	r := &recorder{}
+	c.Check(AssertDecrypts(r, cipher, out, "some secret"), check.Equals, true)
+	c.Check(r.errors, check.HasLen, 0)
+	c.Check(AssertDecrypts(r, cipher, out, "other secret"), check.Equals, false)
+	c.Check(AssertDecrypts(r, NewCipher(c, "default", "other"), out, "some secret"), check.Equals, false)
+	c.Check(r.errors, check.HasLen, 2)
+	c.Check(AssertDecrypts(c, cipher, Encrypt(c, cipher, "x"), "x"), check.Equals, true)
+
+	key := []byte(NewKey("seed").HexString())
+	r = &recorder{}
+	c.Check(AssertDecryptsMode(r, "fips", key, out, "some secret"), check.Equals, true,
+		check.Commentf("%v", r.errors))
+	c.Check(AssertDecryptsMode(r, "workbench", key, out, "some secret"), check.Equals, false)
+	c.Check(AssertDecryptsMode(r, "unknown", key, out, "some secret"), check.Equals, false)
+
+	r = &recorder{}
+	NewCipher(r, "unknown", "seed")
+	c.Check(r.errors, check.DeepEquals, []string{`crypttest: unsupported mode "unknown"`})
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&CrypttestSuite{})
+	check.TestingT(t)
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package csvfile transforms selected columns of RFC 4180 CSV files, such as
+// encrypting a password column, one record at a time.
+package csvfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnknownColumn reports a column that is not in the header.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// MapColumns copies CSV records from src to dst, replacing non-empty fields in
+// the named columns with the result of f. The first record is the header,
+// which names the columns. Line endings match that of the first line. It
+// returns the number of records after the header.
+//
+// Line breaks inside quoted fields are passed to f as "\n", as encoding/csv
+// reads them, and are written with the same line ending as the records, so a
+// file with consistent line endings is copied unchanged. Records are written
+// as soon as they are read whenever no more input is waiting, so MapColumns
+// can be used in a pipeline.
+//
+// Errors identify fields by record and column, never by their contents.
+func MapColumns(dst io.Writer, src io.Reader, columns []string, f func(string) (string, error)) (int, error) {
+	eol := &eolReader{r: src}
+	in := bufio.NewReader(eol)
+	r := csv.NewReader(in)
+	r.ReuseRecord = true
+	w := csv.NewWriter(dst)
+	record, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	// Records are reused, so the header must be copied.
+	header := append([]string(nil), record...)
+	indexes := make([]int, 0, len(columns))
+	for _, name := range columns {
+		i := indexOf(header, name)
+		if i < 0 {
+			return 0, fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+		indexes = append(indexes, i)
+	}
+	// The header has been read, so the first line ending has been seen.
+	w.UseCRLF = eol.crlf
+	if err := w.Write(header); err != nil {
+		return 0, err
+	}
+	n := 0
+	for {
+		// Flush before the next read might wait for more input.
+		if in.Buffered() == 0 {
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return n, err
+			}
+		}
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+		for _, i := range indexes {
+			if record[i] == "" {
+				continue
+			}
+			record[i], err = f(record[i])
+			if err != nil {
+				return n, fmt.Errorf("record %d, column %s: %w", n, header[i], err)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return n, err
+		}
+	}
+	w.Flush()
+	return n, w.Error()
+}
+
+// eolReader records whether the first line read through it ends in "\r\n".
+type eolReader struct {
+	r    io.Reader
+	prev byte
+	// Whether the first line ending has been seen, and if it was "\r\n".
+	seen, crlf bool
+}
+
+// Read implements io.Reader.
+func (e *eolReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if !e.seen && n > 0 {
+		if i := bytes.IndexByte(p[:n], '\n'); i >= 0 {
+			e.seen = true
+			e.crlf = i > 0 && p[i-1] == '\r' || i == 0 && e.prev == '\r'
+		} else {
+			e.prev = p[n-1]
+		}
+	}
+	return n, err
+}
+
+func indexOf(header []string, name string) int {
+	for i, h := range header {
+		if h == name {
+			return i
+		}
+	}
+	return -1
+}
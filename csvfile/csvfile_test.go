@@ -0,0 +1,111 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package csvfile
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+type CSVSuite struct{}
+
+func upper(s string) (string, error) {
+	return strings.ToUpper(s), nil
+}
+
+func (s *CSVSuite) TestMapColumns(c *check.C) {
+	in := "name,password,token\r\n" +
+		"alice,\"p,w\"\"1\",t1\r\n" +
+		"bob,,\"multi\r\nline\"\r\n"
+	var out strings.Builder
+	n, err := MapColumns(&out, strings.NewReader(in), []string{"password", "token"}, upper)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 2)
+	c.Check(out.String(), check.Equals, "name,password,token\r\n"+
+		"alice,\"P,W\"\"1\",T1\r\n"+
+		"bob,,\"MULTI\r\nLINE\"\r\n")
+
+	out.Reset()
+	n, err = MapColumns(&out, strings.NewReader("a,b\n1,2\n"), []string{"b"}, upper)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 1)
+	c.Check(out.String(), check.Equals, "a,b\n1,2\n")
+
+	_, err = MapColumns(&out, strings.NewReader("a,b\n"), []string{"c"}, upper)
+	c.Check(err, check.ErrorMatches, `unknown column "c"`)
+	c.Check(errors.Is(err, ErrUnknownColumn), check.Equals, true)
+
+	// Errors do not include the contents of fields.
+	fail := func(string) (string, error) { return "", errors.New("failed") }
+	_, err = MapColumns(&out, strings.NewReader("a,b\n1,secret\n"), []string{"b"}, fail)
+	c.Check(err, check.ErrorMatches, `record 1, column b: failed`)
+
+	// Records must have the same number of fields.
+	_, err = MapColumns(&out, strings.NewReader("a,b\n1,2,3\n"), []string{"b"}, upper)
+	c.Check(err, check.ErrorMatches, `.*wrong number of fields`)
+
+	n, err = MapColumns(&out, strings.NewReader(""), []string{"b"}, upper)
+	c.Check(err, check.IsNil)
+	c.Check(n, check.Equals, 0)
+}
+
+func (s *CSVSuite) TestMapColumnsLineEndings(c *check.C) {
+	// Only the first line decides the line ending, even when it is split
+	// across reads.
+	in := io.MultiReader(strings.NewReader("a,b\r"), strings.NewReader("\n1,\"x\ny\"\n"))
+	var out strings.Builder
+	_, err := MapColumns(&out, in, []string{"b"}, upper)
+	c.Assert(err, check.IsNil)
+	c.Check(out.String(), check.Equals, "a,b\r\n1,\"X\r\nY\"\r\n")
+
+	// Line breaks in fields are passed on as "\n".
+	var fields []string
+	record := func(s string) (string, error) {
+		fields = append(fields, s)
+		return s, nil
+	}
+	out.Reset()
+	_, err = MapColumns(&out, strings.NewReader("a,b\n1,\"x\r\ny\"\n"), []string{"b"}, record)
+	c.Assert(err, check.IsNil)
+	c.Check(fields, check.DeepEquals, []string{"x\ny"})
+	c.Check(out.String(), check.Equals, "a,b\n1,\"x\ny\"\n")
+}
+
+func (s *CSVSuite) TestMapColumnsStreams(c *check.C) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := MapColumns(outW, inR, []string{"b"}, upper)
+		outW.CloseWithError(err)
+		done <- err
+	}()
+	go inW.Write([]byte("a,b\n1,x\n"))
+
+	// The first record is written before the input ends.
+	lines := bufio.NewReader(outR)
+	for _, want := range []string{"a,b\n", "1,X\n"} {
+		line, err := lines.ReadString('\n')
+		c.Assert(err, check.IsNil)
+		c.Check(line, check.Equals, want)
+	}
+	go func() {
+		inW.Write([]byte("2,y\n"))
+		inW.Close()
+	}()
+	rest, err := io.ReadAll(lines)
+	c.Assert(err, check.IsNil)
+	c.Check(string(rest), check.Equals, "2,Y\n")
+	c.Check(<-done, check.IsNil)
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&CSVSuite{})
+	check.TestingT(t)
+}
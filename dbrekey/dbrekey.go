@@ -0,0 +1,214 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dbrekey re-encrypts secrets stored in a database column under a new
+// key.
+package dbrekey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+// PlaceholderStyle is the style of bind parameters used by a database driver.
+type PlaceholderStyle int
+
+const (
+	// Question uses "?" placeholders, as SQLite and MySQL do.
+	Question PlaceholderStyle = iota
+	// Dollar uses "$1" placeholders, as PostgreSQL does.
+	Dollar
+)
+
+// DefaultBatchSize is the number of rows re-encrypted in each transaction when
+// Options.BatchSize is not set.
+const DefaultBatchSize = 100
+
+// Options describe a column to re-encrypt.
+type Options struct {
+	// Table is the name of the table, optionally qualified with a schema.
+	Table string
+	// Column is the name of the column containing cipher text.
+	Column string
+	// IDColumn is a unique, sortable column used to page through the
+	// table, such as its primary key.
+	IDColumn string
+	// Old is used to decrypt existing values.
+	Old crypt.Cipher
+	// New is used to encrypt values.
+	New crypt.Cipher
+	// BatchSize is the number of rows re-encrypted in each transaction.
+	BatchSize int
+	// Placeholders is the bind parameter style for the database driver.
+	Placeholders PlaceholderStyle
+	// DryRun, if true, rolls back every transaction instead of committing.
+	DryRun bool
+}
+
+// Result summarizes a completed or partial re-encryption.
+type Result struct {
+	// Rekeyed is the number of values re-encrypted with the new key.
+	Rekeyed int
+	// Skipped is the number of NULL or empty values, and values that were
+	// already encrypted with the new key in its current format.
+	Skipped int
+	// Batches is the number of batches processed.
+	Batches int
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// quote returns a quoted SQL identifier, or an error if the name is not a
+// plain identifier.
+func quote(name string) (string, error) {
+	if !identifier.MatchString(name) {
+		return "", fmt.Errorf("invalid SQL identifier %q", name)
+	}
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = `"` + p + `"`
+	}
+	return strings.Join(parts, "."), nil
+}
+
+func (o *Options) placeholder(n int) string {
+	if o.Placeholders == Dollar {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Rekey re-encrypts every value in a column, in batches of rows that are each
+// updated inside a transaction. Values that are already encrypted with the new
+// key, in the format it would use now, are left alone, so an interrupted run
+// can simply be repeated. A row is only
+// updated if its value has not changed since it was read.
+func Rekey(ctx context.Context, db *sql.DB, opts Options) (Result, error) {
+	var result Result
+	if opts.Old == nil || opts.New == nil {
+		return result, errors.New("both old and new keys must be provided")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	table, err := quote(opts.Table)
+	if err != nil {
+		return result, err
+	}
+	column, err := quote(opts.Column)
+	if err != nil {
+		return result, err
+	}
+	id, err := quote(opts.IDColumn)
+	if err != nil {
+		return result, err
+	}
+	first := fmt.Sprintf("SELECT %s, %s FROM %s ORDER BY %s LIMIT %d",
+		id, column, table, id, opts.BatchSize)
+	next := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s > %s ORDER BY %s LIMIT %d",
+		id, column, table, id, opts.placeholder(1), id, opts.BatchSize)
+	update := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s AND %s = %s",
+		table, column, opts.placeholder(1), id, opts.placeholder(2), column,
+		opts.placeholder(3))
+
+	var last any
+	for {
+		n, lastID, err := rekeyBatch(ctx, db, &opts, &result, func(tx *sql.Tx) (*sql.Rows, error) {
+			if last == nil {
+				return tx.QueryContext(ctx, first)
+			}
+			return tx.QueryContext(ctx, next, last)
+		}, update)
+		if err != nil {
+			return result, err
+		}
+		if n == 0 {
+			return result, nil
+		}
+		last = lastID
+	}
+}
+
+type row struct {
+	id    any
+	value sql.NullString
+}
+
+// rekeyBatch re-encrypts a single batch of rows in a transaction, returning
+// the number of rows read and the last ID seen.
+func rekeyBatch(ctx context.Context, db *sql.DB, opts *Options, result *Result,
+	query func(*sql.Tx) (*sql.Rows, error), update string) (int, any, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := query(tx)
+	if err != nil {
+		return 0, nil, err
+	}
+	batch := []row{}
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.value); err != nil {
+			rows.Close()
+			return 0, nil, err
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	if len(batch) == 0 {
+		return 0, nil, nil
+	}
+
+	rekeyed, skipped := 0, 0
+	for _, r := range batch {
+		if !r.value.Valid || r.value.String == "" {
+			skipped++
+			continue
+		}
+		// Values that the new key can decrypt may still need re-encrypting,
+		// for example from the default mode to FIPS mode with the same key.
+		plaintext, err := opts.New.Decrypt(r.value.String)
+		if err != nil {
+			if plaintext, err = opts.Old.Decrypt(r.value.String); err != nil {
+				return 0, nil, fmt.Errorf("row %v: %w", r.id, err)
+			}
+		}
+		ciphertext, err := crypt.EncryptStable(opts.New, plaintext, r.value.String)
+		if err != nil {
+			return 0, nil, fmt.Errorf("row %v: %w", r.id, err)
+		}
+		if ciphertext == r.value.String {
+			skipped++
+			continue
+		}
+		res, err := tx.ExecContext(ctx, update, ciphertext, r.id, r.value.String)
+		if err != nil {
+			return 0, nil, fmt.Errorf("row %v: %w", r.id, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n != 1 {
+			return 0, nil, fmt.Errorf("row %v: changed during re-encryption", r.id)
+		}
+		rekeyed++
+	}
+	if !opts.DryRun {
+		if err := tx.Commit(); err != nil {
+			return 0, nil, err
+		}
+	}
+	result.Rekeyed += rekeyed
+	result.Skipped += skipped
+	result.Batches++
+	return len(batch), batch[len(batch)-1].id, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package dbrekey
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"gopkg.in/check.v1"
+	_ "modernc.org/sqlite"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/crypttest"
+)
+
+type RekeySuite struct {
+	db  *sql.DB
+	old crypt.Cipher
+	new crypt.Cipher
+}
+
+func (s *RekeySuite) SetUpTest(c *check.C) {
+	db, err := sql.Open("sqlite", c.MkDir()+"/test.db")
+	c.Assert(err, check.IsNil)
+	s.db = db
+	s.old = crypttest.NewKey("old")
+	s.new = crypttest.NewKey("new").FIPSCipher()
+
+	_, err = db.Exec(`CREATE TABLE creds (id INTEGER PRIMARY KEY, name TEXT, password TEXT)`)
+	c.Assert(err, check.IsNil)
+	for i := 1; i <= 25; i++ {
+		cipher, err := s.old.Encrypt(fmt.Sprintf("secret-%d", i))
+		c.Assert(err, check.IsNil)
+		_, err = db.Exec(`INSERT INTO creds VALUES (?, ?, ?)`, i, fmt.Sprintf("user-%d", i), cipher)
+		c.Assert(err, check.IsNil)
+	}
+	_, err = db.Exec(`INSERT INTO creds VALUES (26, 'no-password', NULL)`)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *RekeySuite) TearDownTest(c *check.C) {
+	s.db.Close()
+}
+
+func (s *RekeySuite) options() Options {
+	return Options{
+		Table:     "creds",
+		Column:    "password",
+		IDColumn:  "id",
+		Old:       s.old,
+		New:       s.new,
+		BatchSize: 10,
+	}
+}
+
+// passwords reads the password column using the Secret type.
+func (s *RekeySuite) passwords(c *check.C, cipher crypt.Cipher) map[int]string {
+	rows, err := s.db.Query(`SELECT id, password FROM creds ORDER BY id`)
+	c.Assert(err, check.IsNil)
+	defer rows.Close()
+	out := map[int]string{}
+	for rows.Next() {
+		var id int
+		var secret sql.Null[crypt.Secret]
+		secret.V.Bind(cipher)
+		c.Assert(rows.Scan(&id, &secret), check.IsNil)
+		if !secret.Valid {
+			continue
+		}
+		text, err := secret.V.Reveal()
+		c.Assert(err, check.IsNil)
+		out[id] = text
+	}
+	return out
+}
+
+func (s *RekeySuite) TestRekey(c *check.C) {
+	before := s.passwords(c, s.old)
+	c.Check(before, check.HasLen, 25)
+
+	// A dry run changes nothing.
+	opts := s.options()
+	opts.DryRun = true
+	result, err := Rekey(context.Background(), s.db, opts)
+	c.Assert(err, check.IsNil)
+	c.Check(result, check.Equals, Result{Rekeyed: 25, Skipped: 1, Batches: 3})
+	c.Check(s.passwords(c, s.old), check.DeepEquals, before)
+
+	result, err = Rekey(context.Background(), s.db, s.options())
+	c.Assert(err, check.IsNil)
+	c.Check(result, check.Equals, Result{Rekeyed: 25, Skipped: 1, Batches: 3})
+	c.Check(s.passwords(c, s.new), check.DeepEquals, before)
+
+	// Running again is safe.
+	result, err = Rekey(context.Background(), s.db, s.options())
+	c.Assert(err, check.IsNil)
+	c.Check(result, check.Equals, Result{Rekeyed: 0, Skipped: 26, Batches: 3})
+}
+
+func (s *RekeySuite) TestRekeySameKey(c *check.C) {
+	// Moving to FIPS mode with the same key re-encrypts every value once, if
+	// the format changes.
+	before := s.passwords(c, s.old)
+	opts := s.options()
+	opts.New = crypttest.NewKey("old").FIPSCipher()
+	sample, _ := s.old.Encrypt("x")
+	want := Result{Rekeyed: 25, Skipped: 1, Batches: 3}
+	if stable, _ := crypt.EncryptStable(opts.New, "x", sample); stable == sample {
+		want = Result{Rekeyed: 0, Skipped: 26, Batches: 3}
+	}
+	result, err := Rekey(context.Background(), s.db, opts)
+	c.Assert(err, check.IsNil)
+	c.Check(result, check.Equals, want)
+	c.Check(s.passwords(c, opts.New), check.DeepEquals, before)
+
+	result, err = Rekey(context.Background(), s.db, opts)
+	c.Assert(err, check.IsNil)
+	c.Check(result, check.Equals, Result{Rekeyed: 0, Skipped: 26, Batches: 3})
+}
+
+func (s *RekeySuite) TestRekeyFailure(c *check.C) {
+	// A value that neither key can decrypt stops the batch it is in, but
+	// earlier batches are kept.
+	other, _ := crypttest.NewKey("other").Encrypt("secret")
+	_, err := s.db.Exec(`UPDATE creds SET password = ? WHERE id = 15`, other)
+	c.Assert(err, check.IsNil)
+
+	result, err := Rekey(context.Background(), s.db, s.options())
+	c.Check(err, check.ErrorMatches, `row 15: Decryption failed`)
+	c.Check(result, check.Equals, Result{Rekeyed: 10, Batches: 1})
+
+	rows, err := s.db.Query(`SELECT id, password FROM creds WHERE id IN (10, 11)`)
+	c.Assert(err, check.IsNil)
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var value string
+		c.Assert(rows.Scan(&id, &value), check.IsNil)
+		_, err := s.new.Decrypt(value)
+		c.Check(err == nil, check.Equals, id == 10)
+	}
+}
+
+func (s *RekeySuite) TestOptions(c *check.C) {
+	opts := s.options()
+	opts.Table = "creds; DROP TABLE creds"
+	_, err := Rekey(context.Background(), s.db, opts)
+	c.Check(err, check.ErrorMatches, `invalid SQL identifier .*`)
+
+	opts = s.options()
+	opts.New = nil
+	_, err = Rekey(context.Background(), s.db, opts)
+	c.Check(err, check.ErrorMatches, `both old and new keys must be provided`)
+
+	opts = s.options()
+	c.Check(opts.placeholder(2), check.Equals, "?")
+	opts.Placeholders = Dollar
+	c.Check(opts.placeholder(2), check.Equals, "$2")
+
+	q, err := quote("public.creds")
+	c.Check(err, check.IsNil)
+	c.Check(q, check.Equals, `"public"."creds"`)
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&RekeySuite{})
+	check.TestingT(t)
+}
@@ -0,0 +1,305 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package document encrypts and decrypts selected string values in JSON, YAML,
+// and TOML documents, such as Helm values files or R config.yml files.
+//
+// Values are replaced in place, so comments, ordering, and layout are always
+// preserved. Encrypted values are wrapped in a marker, ENC[...], so that
+// encrypting a document again leaves them alone.
+package document
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+// Format identifies a document syntax.
+type Format string
+
+// The supported formats.
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+	TOML Format = "toml"
+)
+
+const (
+	// MarkerPrefix and MarkerSuffix surround encrypted values.
+	MarkerPrefix = "ENC["
+	MarkerSuffix = "]"
+)
+
+var (
+	// ErrUnknownFormat reports a format that is not supported.
+	ErrUnknownFormat = errors.New("unknown document format")
+	// ErrNotFound reports a path that does not refer to a string value.
+	ErrNotFound = errors.New("no string value at path")
+)
+
+// Formats lists the supported formats.
+func Formats() []Format {
+	return []Format{JSON, YAML, TOML}
+}
+
+// ParseFormat returns the Format with the given name.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats() {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownFormat, s)
+}
+
+// DetectFormat guesses the format of a file from its name.
+func DetectFormat(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return JSON, nil
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".toml":
+		return TOML, nil
+	}
+	return "", fmt.Errorf("%w for %s", ErrUnknownFormat, path)
+}
+
+// Value is a string value in a document.
+type Value struct {
+	Path  Path
+	Value string
+}
+
+// value is a string value and the byte range of its source in the document.
+type value struct {
+	Value
+	start, end int
+	// Whether the value was unquoted in the source.
+	plain bool
+	// Why the source of the value cannot be located, if it cannot. Such
+	// values can be read, but not changed.
+	err error
+}
+
+// Document is a parsed document. Only string values can be read or changed.
+type Document struct {
+	format Format
+	data   []byte
+	values []*value
+	// quote returns the source for a new value of v.
+	quote func(v *value, s string) string
+}
+
+// Parse parses a document in the given format.
+func Parse(format Format, data []byte) (*Document, error) {
+	d := &Document{format: format, data: data}
+	var err error
+	switch format {
+	case JSON:
+		d.values, err = parseJSON(data)
+		d.quote = quoteJSON
+	case YAML:
+		d.values, err = parseYAML(data)
+		d.quote = quoteYAML
+	case TOML:
+		d.values, err = parseTOML(data)
+		d.quote = quoteTOML
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ReadFile reads and parses a document. If format is empty, it is detected
+// from the file name.
+func ReadFile(path string, format Format) (*Document, error) {
+	if format == "" {
+		var err error
+		if format, err = DetectFormat(path); err != nil {
+			return nil, err
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := Parse(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// Format returns the document's format.
+func (d *Document) Format() Format {
+	return d.format
+}
+
+// Values lists every string value, in document order. Object keys are not
+// included.
+func (d *Document) Values() []Value {
+	out := make([]Value, len(d.values))
+	for i, v := range d.values {
+		out[i] = v.Value
+	}
+	return out
+}
+
+// Get returns the string value at a path.
+func (d *Document) Get(p Path) (string, bool) {
+	if v := d.find(p); v != nil {
+		return v.Value.Value, true
+	}
+	return "", false
+}
+
+func (d *Document) find(p Path) *value {
+	key := p.String()
+	for _, v := range d.values {
+		if v.Path.String() == key {
+			return v
+		}
+	}
+	return nil
+}
+
+// Set replaces an existing string value. Values cannot be added.
+func (d *Document) Set(p Path, s string) error {
+	v := d.find(p)
+	if v == nil {
+		return fmt.Errorf("%w %s", ErrNotFound, p)
+	} else if v.err != nil {
+		return v.err
+	}
+	d.set(v, s)
+	return nil
+}
+
+// SetValue replaces the i-th string value listed by Values.
+func (d *Document) SetValue(i int, s string) error {
+	if i < 0 || i >= len(d.values) {
+		return fmt.Errorf("%w: value %d is out of range", ErrNotFound, i)
+	}
+	v := d.values[i]
+	if v.err != nil {
+		return v.err
+	}
+	d.set(v, s)
+	return nil
+}
+
+func (d *Document) set(v *value, s string) {
+	raw := d.quote(v, s)
+	data := make([]byte, 0, len(d.data)-(v.end-v.start)+len(raw))
+	data = append(data, d.data[:v.start]...)
+	data = append(data, raw...)
+	data = append(data, d.data[v.end:]...)
+	shift := len(raw) - (v.end - v.start)
+	for _, other := range d.values {
+		if other.start > v.start {
+			other.start += shift
+			other.end += shift
+		}
+	}
+	d.data = data
+	v.end = v.start + len(raw)
+	v.Value.Value = s
+}
+
+// Bytes returns the document's contents, including any changes.
+func (d *Document) Bytes() []byte {
+	return d.data
+}
+
+// IsMarked reports whether s is wrapped in the encrypted value marker.
+func IsMarked(s string) bool {
+	return len(s) > len(MarkerPrefix)+len(MarkerSuffix) &&
+		strings.HasPrefix(s, MarkerPrefix) && strings.HasSuffix(s, MarkerSuffix)
+}
+
+// Mark wraps cipher text in the encrypted value marker.
+func Mark(ciphertext string) string {
+	return MarkerPrefix + ciphertext + MarkerSuffix
+}
+
+// Unmark removes the encrypted value marker from s, which must be marked.
+func Unmark(s string) string {
+	return s[len(MarkerPrefix) : len(s)-len(MarkerSuffix)]
+}
+
+// Encrypt encrypts the values selected by m that are not already encrypted,
+// returning how many were changed. With marker, encrypted values are wrapped
+// in ENC[...] and values that already are are skipped. Without it, values that
+// c can already decrypt are skipped instead.
+func (d *Document) Encrypt(c crypt.Cipher, m Matcher, marker bool) (int, error) {
+	n := 0
+	for _, v := range d.values {
+		s := v.Value.Value
+		if !m.Match(v.Path) || IsMarked(s) {
+			continue
+		}
+		if !marker {
+			if _, err := c.Decrypt(s); err == nil {
+				continue
+			}
+		}
+		if v.err != nil {
+			return n, v.err
+		}
+		ciphertext, err := c.Encrypt(s)
+		if err != nil {
+			return n, fmt.Errorf("%s: %w", v.Path, err)
+		}
+		if marker {
+			ciphertext = Mark(ciphertext)
+		}
+		d.set(v, ciphertext)
+		n++
+	}
+	return n, nil
+}
+
+// Decrypt decrypts the values selected by m, returning how many were changed.
+// Values wrapped in ENC[...] must decrypt successfully. Other values are only
+// decrypted without marker, and are left alone if they cannot be, since they
+// are most likely plain text already.
+func (d *Document) Decrypt(c crypt.Cipher, m Matcher, marker bool) (int, error) {
+	n := 0
+	for _, v := range d.values {
+		s := v.Value.Value
+		if !m.Match(v.Path) {
+			continue
+		}
+		var plaintext string
+		var err error
+		switch {
+		case IsMarked(s):
+			plaintext, err = c.Decrypt(Unmark(s))
+			if err != nil {
+				return n, fmt.Errorf("%s: %w", v.Path, err)
+			}
+		case marker:
+			continue
+		default:
+			plaintext, err = c.Decrypt(s)
+			if err != nil {
+				continue
+			}
+		}
+		if v.err != nil {
+			return n, v.err
+		}
+		d.set(v, plaintext)
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,293 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/crypttest"
+)
+
+type DocumentSuite struct {
+	key *crypt.Key
+}
+
+func (s *DocumentSuite) SetUpTest(c *check.C) {
+	s.key = crypttest.NewKey("document")
+}
+
+func (s *DocumentSuite) selector(c *check.C, text string) *Selector {
+	sel, err := ParseSelector(text)
+	c.Assert(err, check.IsNil)
+	return sel
+}
+
+// paths lists the paths of the string values in a document.
+func paths(d *Document) []string {
+	out := []string{}
+	for _, v := range d.Values() {
+		out = append(out, v.Path.String()+"="+v.Value)
+	}
+	return out
+}
+
+func (s *DocumentSuite) TestSelector(c *check.C) {
+	for text, matches := range map[string][]Path{
+		".connect.db.password":  {{"connect", "db", "password"}},
+		"$.users[1].token":      {{"users", 1, "token"}},
+		".users[*].token":       {{"users", 0, "token"}, {"users", 7, "token"}},
+		`.servers.*["api key"]`: {{"servers", "a", "api key"}},
+		`.a['b.c']`:             {{"a", "b.c"}},
+		".":                     {{}},
+	} {
+		sel := s.selector(c, text)
+		for _, p := range matches {
+			c.Check(sel.Match(p), check.Equals, true, check.Commentf("%s %s", text, p))
+			c.Check(sel.Match(append(p, "extra")), check.Equals, false)
+		}
+	}
+	c.Check(s.selector(c, ".a.b").Match(Path{"a", "c"}), check.Equals, false)
+	c.Check(s.selector(c, ".a[0]").Match(Path{"a", "0"}), check.Equals, false)
+
+	for _, bad := range []string{"a", ".a..b", ".a[", ".a[x]", `.a["b]`, ".a[-1]"} {
+		_, err := ParseSelector(bad)
+		c.Check(err, check.ErrorMatches, `invalid selector .*`, check.Commentf("%s", bad))
+	}
+
+	c.Check(Path{"a", 0, "b c"}.String(), check.Equals, `.a[0]["b c"]`)
+	k := KeyPattern{regexp.MustCompile(`(?i)password`)}
+	c.Check(k.Match(Path{"db", "Password"}), check.Equals, true)
+	c.Check(k.Match(Path{"passwords", 2}), check.Equals, true)
+	c.Check(k.Match(Path{"password", "user"}), check.Equals, false)
+	c.Check(AnyOf{k, s.selector(c, ".user")}.Match(Path{"user"}), check.Equals, true)
+}
+
+func (s *DocumentSuite) TestJSON(c *check.C) {
+	d, err := Parse(JSON, []byte(`{
+  "connect": {"db": {"password": "hunter2", "port": 5432}},
+  "tokens": ["a", "b\"c"],
+  "enabled": true
+}
+`))
+	c.Assert(err, check.IsNil)
+	c.Check(paths(d), check.DeepEquals, []string{
+		".connect.db.password=hunter2", ".tokens[0]=a", `.tokens[1]=b"c`,
+	})
+	c.Assert(d.Set(Path{"tokens", 1}, "x</y>"), check.IsNil)
+	c.Assert(d.Set(Path{"connect", "db", "password"}, "é"), check.IsNil)
+	c.Assert(d.SetValue(1, "z"), check.IsNil)
+	c.Check(string(d.Bytes()), check.Equals, `{
+  "connect": {"db": {"password": "é", "port": 5432}},
+  "tokens": ["z", "x</y>"],
+  "enabled": true
+}
+`)
+	c.Check(d.Set(Path{"enabled"}, "x"), check.ErrorMatches, `no string value at path .enabled`)
+	c.Check(d.SetValue(3, "x"), check.ErrorMatches, `no string value at path: value 3 is out of range`)
+
+	_, err = Parse(JSON, []byte(`{"a": [`))
+	c.Check(err, check.NotNil)
+}
+
+func (s *DocumentSuite) TestYAML(c *check.C) {
+	d, err := Parse(YAML, []byte(`# Helm values
+connect:
+  db:
+    password: hunter2  # the password
+    user: 'conn''ect'
+  tokens: ["a", b]
+  port: 5432
+  literal: |
+    not replaceable
+`))
+	// Values that cannot be located only fail when they are changed.
+	c.Assert(err, check.IsNil)
+	value, _ := d.Get(Path{"connect", "literal"})
+	c.Check(value, check.Equals, "not replaceable\n")
+	c.Check(d.Set(Path{"connect", "literal"}, "x"), check.ErrorMatches,
+		`.connect.literal: block scalars at line 8 are not supported`)
+
+	d, err = Parse(YAML, []byte(`# Helm values
+connect:
+  db:
+    password: hunter2  # the password
+    user: 'conn''ect'
+  tokens: ["a", b]
+  port: 5432
+  name: 名前
+`))
+	c.Assert(err, check.IsNil)
+	c.Check(paths(d), check.DeepEquals, []string{
+		".connect.db.password=hunter2", ".connect.db.user=conn'ect",
+		".connect.tokens[0]=a", ".connect.tokens[1]=b", ".connect.name=名前",
+	})
+	c.Assert(d.Set(Path{"connect", "db", "user"}, "new"), check.IsNil)
+	c.Assert(d.Set(Path{"connect", "db", "password"}, "abc="), check.IsNil)
+	c.Assert(d.Set(Path{"connect", "tokens", 1}, "ENC[x]"), check.IsNil)
+	c.Assert(d.Set(Path{"connect", "name"}, "123"), check.IsNil)
+	c.Check(string(d.Bytes()), check.Equals, `# Helm values
+connect:
+  db:
+    password: abc=  # the password
+    user: "new"
+  tokens: ["a", "ENC[x]"]
+  port: 5432
+  name: "123"
+`)
+
+	_, err = Parse(YAML, []byte("a: 1\n---\nb: 2\n"))
+	c.Check(err, check.ErrorMatches, `multiple YAML documents are not supported`)
+	d, err = Parse(YAML, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(d.Values(), check.HasLen, 0)
+}
+
+func (s *DocumentSuite) TestTOML(c *check.C) {
+	d, err := Parse(TOML, []byte(`# Service configuration
+title = 'literal'
+
+[db]
+password = "hun\"ter2" # the password
+port = 5432
+"quoted.key" = "q"
+notes = """
+not "replaceable"
+"""
+
+[[servers]]
+token = "a"
+[[servers]]
+token = "b"
+auth = { user = "u", secret = "s" }
+[servers.extra]
+list = [
+  "x", # comment
+  'y',
+]
+`))
+	c.Assert(err, check.IsNil)
+	c.Check(paths(d), check.DeepEquals, []string{
+		".title=literal", `.db.password=hun"ter2`, `.db["quoted.key"]=q`,
+		".db.notes=not \"replaceable\"\n", ".servers[0].token=a", ".servers[1].token=b",
+		".servers[1].auth.user=u", ".servers[1].auth.secret=s",
+		".servers[1].extra.list[0]=x", ".servers[1].extra.list[1]=y",
+	})
+	// Multi-line strings can be read, but not changed.
+	c.Check(d.Set(Path{"db", "notes"}, "x"), check.ErrorMatches,
+		`.db.notes: line 8: multi-line strings are not supported`)
+	_, err = d.Encrypt(s.key, s.selector(c, ".db.notes"), false)
+	c.Check(err, check.ErrorMatches, `.db.notes: .*`)
+	c.Assert(d.Set(Path{"title"}, "new\ttitle"), check.IsNil)
+	c.Assert(d.Set(Path{"servers", 1, "auth", "secret"}, "ENC[s]"), check.IsNil)
+	c.Check(string(d.Bytes()), check.Matches, `(?s)# Service configuration
+title = "new\\ttitle"
+.*auth = \{ user = "u", secret = "ENC\[s\]" \}
+.*`)
+	d, err = Parse(TOML, d.Bytes())
+	c.Assert(err, check.IsNil)
+	value, _ := d.Get(Path{"title"})
+	c.Check(value, check.Equals, "new\ttitle")
+
+	_, err = Parse(TOML, []byte("a = \n"))
+	c.Check(err, check.NotNil)
+}
+
+func (s *DocumentSuite) TestEncrypt(c *check.C) {
+	d, err := Parse(YAML, []byte("db:\n  password: hunter2\n  user: connect\napi:\n  token: abc\n"))
+	c.Assert(err, check.IsNil)
+	m := AnyOf{s.selector(c, ".db.password"), KeyPattern{regexp.MustCompile(`token`)}}
+	n, err := d.Encrypt(s.key, m, true)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 2)
+	out := string(d.Bytes())
+	c.Check(strings.Contains(out, "hunter2"), check.Equals, false)
+	c.Check(out, check.Matches, `(?s).*user: connect\n.*`)
+
+	// Encrypting again is safe.
+	n, err = d.Encrypt(s.key, m, true)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 0)
+	c.Check(string(d.Bytes()), check.Equals, out)
+
+	value, _ := d.Get(Path{"db", "password"})
+	c.Check(IsMarked(value), check.Equals, true)
+
+	// Unmarked values are left alone.
+	_, err = d.Decrypt(s.key, AnyOf{s.selector(c, ".db.user")}, true)
+	c.Check(err, check.IsNil)
+
+	_, err = d.Decrypt(crypttest.NewKey("other"), m, true)
+	c.Check(err, check.ErrorMatches, `.db.password: Decryption failed`)
+	n, err = d.Decrypt(s.key, m, true)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 2)
+	// Plain values stay plain where possible.
+	c.Check(string(d.Bytes()), check.Equals,
+		"db:\n  password: hunter2\n  user: connect\napi:\n  token: abc\n")
+
+	// Without markers, values that already decrypt are skipped.
+	d, err = Parse(JSON, []byte(`{"password": "hunter2"}`))
+	c.Assert(err, check.IsNil)
+	sel := s.selector(c, ".password")
+	n, _ = d.Encrypt(s.key, sel, false)
+	c.Check(n, check.Equals, 1)
+	ciphertext, _ := d.Get(Path{"password"})
+	plaintext, err := s.key.Decrypt(ciphertext)
+	c.Check(err, check.IsNil)
+	c.Check(plaintext, check.Equals, "hunter2")
+	n, _ = d.Encrypt(s.key, sel, false)
+	c.Check(n, check.Equals, 0)
+	n, err = d.Decrypt(s.key, sel, false)
+	c.Check(err, check.IsNil)
+	c.Check(n, check.Equals, 1)
+	c.Check(string(d.Bytes()), check.Equals, `{"password": "hunter2"}`)
+	n, err = d.Decrypt(s.key, sel, false)
+	c.Check(err, check.IsNil)
+	c.Check(n, check.Equals, 0)
+}
+
+func (s *DocumentSuite) TestEncryptYAMLNeighbours(c *check.C) {
+	d, err := Parse(YAML, []byte(`defaults: &defaults
+  user: &user connect
+  password: &password !!str hunter2
+prod:
+  <<: *defaults
+  user: *user
+  motd: |
+    Welcome to
+    Connect
+  description: a plain value
+    spanning two lines
+  token: abc
+`))
+	c.Assert(err, check.IsNil)
+	n, err := d.Encrypt(s.key, AnyOf{s.selector(c, ".defaults.password"), s.selector(c, ".prod.token")}, true)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 2)
+	out := string(d.Bytes())
+	c.Check(out, check.Matches, `(?s).*  password: &password !!str "ENC\[[^"]+\]"\n.*`)
+	c.Check(out, check.Matches, `(?s).*  motd: \|\n    Welcome to\n    Connect\n.*`)
+	c.Check(out, check.Matches, `(?s).*  token: "ENC\[[^"]+\]"\n$`)
+	n, err = d.Decrypt(s.key, AnyOf{s.selector(c, ".defaults.password"), s.selector(c, ".prod.token")}, true)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, 2)
+	value, _ := d.Get(Path{"defaults", "password"})
+	c.Check(value, check.Equals, "hunter2")
+
+	// Selecting a value that cannot be located fails.
+	_, err = d.Encrypt(s.key, s.selector(c, ".prod.motd"), true)
+	c.Check(err, check.ErrorMatches, `.prod.motd: block scalars at line 7 are not supported`)
+	_, err = d.Encrypt(s.key, s.selector(c, ".prod.description"), true)
+	c.Check(err, check.ErrorMatches, `.prod.description: cannot locate value at line 10`)
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&DocumentSuite{})
+	check.TestingT(t)
+}
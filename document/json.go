@@ -0,0 +1,103 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// container tracks the position within a JSON object or array.
+type container struct {
+	object bool
+	// The next array index, or the key of the next object member.
+	index int
+	key   string
+	// Whether the next token in an object is a key.
+	wantKey bool
+}
+
+func parseJSON(data []byte) ([]*value, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var values []*value
+	var stack []*container
+	path := func() Path {
+		p := Path{}
+		for _, c := range stack {
+			if c.object {
+				p = append(p, c.key)
+			} else {
+				p = append(p, c.index)
+			}
+		}
+		return p
+	}
+	// advance moves past a value in the current container.
+	advance := func() {
+		if len(stack) == 0 {
+			return
+		}
+		c := stack[len(stack)-1]
+		if c.object {
+			c.wantKey = true
+		} else {
+			c.index++
+		}
+	}
+	for {
+		before := dec.InputOffset()
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var top *container
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		switch tok := tok.(type) {
+		case json.Delim:
+			switch tok {
+			case '{', '[':
+				stack = append(stack, &container{object: tok == '{', wantKey: true})
+			default:
+				stack = stack[:len(stack)-1]
+				advance()
+			}
+		case string:
+			if top != nil && top.object && top.wantKey {
+				top.key, top.wantKey = tok, false
+				continue
+			}
+			// The token may be preceded by whitespace, ":" or ",".
+			start := before + int64(bytes.IndexByte(data[before:], '"'))
+			values = append(values, &value{
+				Value: Value{Path: path(), Value: tok},
+				start: int(start), end: int(dec.InputOffset()),
+			})
+			advance()
+		default:
+			advance()
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unexpected end of JSON input")
+	}
+	return values, nil
+}
+
+// quoteJSON returns s as a JSON string.
+func quoteJSON(_ *value, s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return string(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSelector reports a selector that cannot be parsed.
+var ErrInvalidSelector = errors.New("invalid selector")
+
+// Path locates a value in a document. Each element is either an object key
+// (a string) or an array index (an int).
+type Path []any
+
+// String returns the path in the syntax accepted by ParseSelector, such as
+// .connect.db.password or .users[0]["api key"].
+func (p Path) String() string {
+	if len(p) == 0 {
+		return "."
+	}
+	var b strings.Builder
+	for _, e := range p {
+		switch e := e.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", e)
+		case string:
+			if bareKey.MatchString(e) {
+				b.WriteString("." + e)
+			} else {
+				b.WriteString("[" + strconv.Quote(e) + "]")
+			}
+		}
+	}
+	return b.String()
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// key returns the last object key in the path, if any.
+func (p Path) key() (string, bool) {
+	for i := len(p) - 1; i >= 0; i-- {
+		if k, ok := p[i].(string); ok {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// Matcher selects values in a document by their path.
+type Matcher interface {
+	Match(p Path) bool
+}
+
+// wildcard matches any key or index in a Selector.
+type wildcard struct{}
+
+// Selector is a JSONPath-style selector, such as $.connect.db.password,
+// .users[*].token, or .servers.*["api key"]. The leading $ is optional.
+type Selector struct {
+	steps []any
+}
+
+// ParseSelector parses a selector.
+func ParseSelector(s string) (*Selector, error) {
+	rest := strings.TrimPrefix(s, "$")
+	sel := &Selector{}
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidSelector, s, reason)
+	}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			switch name {
+			case "":
+				if rest == "" && len(sel.steps) == 0 {
+					// "." alone selects the root.
+					return sel, nil
+				}
+				return nil, invalid("empty key")
+			case "*":
+				sel.steps = append(sel.steps, wildcard{})
+			default:
+				sel.steps = append(sel.steps, name)
+			}
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, invalid("missing ]")
+			}
+			inner := rest[1:end]
+			if len(inner) > 0 && (inner[0] == '"' || inner[0] == '\'') {
+				// Quoted keys may contain "]".
+				q := inner[0]
+				closing := strings.IndexByte(rest[2:], q)
+				if closing < 0 || 2+closing+1 >= len(rest) || rest[2+closing+1] != ']' {
+					return nil, invalid("unterminated quoted key")
+				}
+				sel.steps = append(sel.steps, rest[2:2+closing])
+				rest = rest[2+closing+2:]
+				continue
+			}
+			switch i, err := strconv.Atoi(inner); {
+			case inner == "*":
+				sel.steps = append(sel.steps, wildcard{})
+			case err == nil && i >= 0:
+				sel.steps = append(sel.steps, i)
+			default:
+				return nil, invalid("expected an index, * or a quoted key in []")
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, invalid("expected . or [")
+		}
+	}
+	return sel, nil
+}
+
+// Match implements Matcher.
+func (s *Selector) Match(p Path) bool {
+	if len(p) != len(s.steps) {
+		return false
+	}
+	for i, step := range s.steps {
+		if _, ok := step.(wildcard); !ok && step != p[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// KeyPattern matches values whose key matches a regular expression. The key of
+// an array element is the key of the array.
+type KeyPattern struct {
+	*regexp.Regexp
+}
+
+// Match implements Matcher.
+func (k KeyPattern) Match(p Path) bool {
+	key, ok := p.key()
+	return ok && k.MatchString(key)
+}
+
+// AnyOf matches values matched by any of its Matchers.
+type AnyOf []Matcher
+
+// Match implements Matcher.
+func (a AnyOf) Match(p Path) bool {
+	for _, m := range a {
+		if m.Match(p) {
+			return true
+		}
+	}
+	return false
+}
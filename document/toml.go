@@ -0,0 +1,386 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlScanner finds the string values in a TOML document. It assumes that the
+// document is valid. Multi-line strings are never selected.
+type tomlScanner struct {
+	data   []byte
+	pos    int
+	values []*value
+	// The path of the current table.
+	table Path
+	// The number of entries in each array of tables, by path.
+	arrays map[string]int
+}
+
+func parseTOML(data []byte) ([]*value, error) {
+	var v map[string]any
+	if _, err := toml.Decode(string(data), &v); err != nil {
+		return nil, err
+	}
+	s := &tomlScanner{data: data, arrays: map[string]int{}}
+	for {
+		s.skipSpace(true)
+		if s.eof() {
+			break
+		}
+		var err error
+		if s.peek() == '[' {
+			err = s.header()
+		} else {
+			err = s.keyValue(s.table)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.values, nil
+}
+
+func (s *tomlScanner) eof() bool {
+	return s.pos >= len(s.data)
+}
+
+func (s *tomlScanner) peek() byte {
+	if s.eof() {
+		return 0
+	}
+	return s.data[s.pos]
+}
+
+func (s *tomlScanner) errorf(format string, args ...any) error {
+	line := bytes.Count(s.data[:min(s.pos, len(s.data))], []byte("\n")) + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (s *tomlScanner) expect(c byte) error {
+	if s.peek() != c {
+		return s.errorf("expected %q", c)
+	}
+	s.pos++
+	return nil
+}
+
+// skipSpace skips whitespace and, if newlines is set, line breaks and
+// comments.
+func (s *tomlScanner) skipSpace(newlines bool) {
+	for !s.eof() {
+		switch c := s.peek(); {
+		case c == ' ' || c == '\t':
+			s.pos++
+		case newlines && (c == '\r' || c == '\n'):
+			s.pos++
+		case c == '#':
+			for !s.eof() && s.peek() != '\n' {
+				s.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// header reads a [table] or [[array]] header.
+func (s *tomlScanner) header() error {
+	s.pos++
+	array := s.peek() == '['
+	if array {
+		s.pos++
+	}
+	keys, err := s.keys()
+	if err != nil {
+		return err
+	}
+	if err := s.expect(']'); err != nil {
+		return err
+	}
+	if array {
+		if err := s.expect(']'); err != nil {
+			return err
+		}
+	}
+	// Keys that name an array of tables refer to its last entry.
+	p := Path{}
+	for i, k := range keys {
+		p = append(p, k)
+		if n, ok := s.arrays[p.String()]; ok && !(array && i == len(keys)-1) {
+			p = append(p, n-1)
+		}
+	}
+	if array {
+		n := s.arrays[p.String()]
+		s.arrays[p.String()] = n + 1
+		p = append(p, n)
+	}
+	s.table = p
+	return nil
+}
+
+// keys reads a dotted key.
+func (s *tomlScanner) keys() ([]string, error) {
+	var keys []string
+	for {
+		s.skipSpace(false)
+		var key string
+		switch c := s.peek(); {
+		case c == '"':
+			k, _, err := s.basicString()
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		case c == '\'':
+			start := s.pos + 1
+			end := bytes.IndexByte(s.data[start:], '\'')
+			if end < 0 {
+				return nil, s.errorf("unterminated key")
+			}
+			key = string(s.data[start : start+end])
+			s.pos = start + end + 1
+		default:
+			start := s.pos
+			for !s.eof() && isBareKey(s.peek()) {
+				s.pos++
+			}
+			if start == s.pos {
+				return nil, s.errorf("expected a key")
+			}
+			key = string(s.data[start:s.pos])
+		}
+		keys = append(keys, key)
+		s.skipSpace(false)
+		if s.peek() != '.' {
+			return keys, nil
+		}
+		s.pos++
+	}
+}
+
+func isBareKey(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-'
+}
+
+// keyValue reads a key = value pair within the table at p.
+func (s *tomlScanner) keyValue(p Path) error {
+	keys, err := s.keys()
+	if err != nil {
+		return err
+	}
+	if err := s.expect('='); err != nil {
+		return err
+	}
+	s.skipSpace(false)
+	p = p[:len(p):len(p)]
+	for _, k := range keys {
+		p = append(p, k)
+	}
+	return s.value(p)
+}
+
+// value reads the value at p.
+func (s *tomlScanner) value(p Path) error {
+	rest := s.data[s.pos:]
+	switch {
+	case bytes.HasPrefix(rest, []byte(`"""`)), bytes.HasPrefix(rest, []byte(`'''`)):
+		// Multi-line strings can be read, but not changed.
+		unsupported := s.errorf("multi-line strings are not supported")
+		start := s.pos
+		if err := s.multiline(); err != nil {
+			return err
+		}
+		// A line break right after the opening quotes is not part of the
+		// string. Escapes are left as they are.
+		str := string(s.data[start+3 : s.pos-3])
+		if rest, ok := strings.CutPrefix(str, "\r\n"); ok {
+			str = rest
+		} else {
+			str = strings.TrimPrefix(str, "\n")
+		}
+		s.values = append(s.values, &value{
+			Value: Value{Path: p, Value: str},
+			err:   fmt.Errorf("%s: %w", p, unsupported),
+		})
+	case s.peek() == '"':
+		start := s.pos
+		str, end, err := s.basicString()
+		if err != nil {
+			return err
+		}
+		s.values = append(s.values, &value{
+			Value: Value{Path: p, Value: str}, start: start, end: end,
+		})
+	case s.peek() == '\'':
+		start := s.pos
+		end := bytes.IndexByte(s.data[start+1:], '\'')
+		if end < 0 {
+			return s.errorf("unterminated string")
+		}
+		s.pos = start + 1 + end + 1
+		s.values = append(s.values, &value{
+			Value: Value{Path: p, Value: string(s.data[start+1 : s.pos-1])},
+			start: start, end: s.pos,
+		})
+	case s.peek() == '[':
+		s.pos++
+		for i := 0; ; i++ {
+			s.skipSpace(true)
+			if s.peek() == ']' {
+				s.pos++
+				return nil
+			}
+			if err := s.value(append(p[:len(p):len(p)], i)); err != nil {
+				return err
+			}
+			s.skipSpace(true)
+			if s.peek() == ',' {
+				s.pos++
+			} else if err := s.expect(']'); err != nil {
+				return err
+			} else {
+				return nil
+			}
+		}
+	case s.peek() == '{':
+		s.pos++
+		for {
+			s.skipSpace(false)
+			if s.peek() == '}' {
+				s.pos++
+				return nil
+			}
+			if err := s.keyValue(p); err != nil {
+				return err
+			}
+			s.skipSpace(false)
+			if s.peek() == ',' {
+				s.pos++
+			} else {
+				return s.expect('}')
+			}
+		}
+	default:
+		// Numbers, booleans, and dates.
+		for !s.eof() && !strings.ContainsRune(" \t\r\n,]}#", rune(s.peek())) {
+			s.pos++
+		}
+	}
+	return nil
+}
+
+// multiline skips a multi-line string.
+func (s *tomlScanner) multiline() error {
+	quote := s.data[s.pos : s.pos+3]
+	s.pos += 3
+	for !s.eof() {
+		if quote[0] == '"' && s.peek() == '\\' {
+			s.pos += 2
+			continue
+		}
+		if bytes.HasPrefix(s.data[s.pos:], quote) {
+			s.pos += 3
+			// Up to two more quotes are part of the string.
+			for i := 0; i < 2 && s.peek() == quote[0]; i++ {
+				s.pos++
+			}
+			return nil
+		}
+		s.pos++
+	}
+	return s.errorf("unterminated string")
+}
+
+// basicString reads a "string", returning its value and end offset.
+func (s *tomlScanner) basicString() (string, int, error) {
+	var b strings.Builder
+	for i := s.pos + 1; i < len(s.data); i++ {
+		c := s.data[i]
+		switch c {
+		case '"':
+			s.pos = i + 1
+			return b.String(), s.pos, nil
+		case '\n':
+			return "", 0, s.errorf("unterminated string")
+		case '\\':
+			i++
+			if i >= len(s.data) {
+				return "", 0, s.errorf("unterminated string")
+			}
+			switch e := s.data[i]; e {
+			case 'b':
+				b.WriteByte('\b')
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			case 'f':
+				b.WriteByte('\f')
+			case 'r':
+				b.WriteByte('\r')
+			case 'e':
+				b.WriteByte(0x1b)
+			case '"', '\\':
+				b.WriteByte(e)
+			case 'x', 'u', 'U':
+				n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+				if i+n >= len(s.data) {
+					return "", 0, s.errorf("invalid escape")
+				}
+				r, err := strconv.ParseUint(string(s.data[i+1:i+1+n]), 16, 32)
+				if err != nil {
+					return "", 0, s.errorf("invalid escape")
+				}
+				b.WriteRune(rune(r))
+				i += n
+			default:
+				return "", 0, s.errorf("invalid escape \\%c", e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, s.errorf("unterminated string")
+}
+
+// quoteTOML returns s as a TOML basic string.
+func quoteTOML(_ *value, s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,169 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+func parseYAML(data []byte) ([]*value, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var root yaml.Node
+	if err := dec.Decode(&root); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var extra yaml.Node
+	if err := dec.Decode(&extra); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("multiple YAML documents are not supported")
+	}
+	lines := lineOffsets(data)
+	var values []*value
+	var walk func(n *yaml.Node, p Path) error
+	walk = func(n *yaml.Node, p Path) error {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				if err := walk(c, p); err != nil {
+					return err
+				}
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k := n.Content[i]
+				if k.Value == "<<" {
+					continue
+				}
+				if err := walk(n.Content[i+1], append(p[:len(p):len(p)], k.Value)); err != nil {
+					return err
+				}
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				if err := walk(c, append(p[:len(p):len(p)], i)); err != nil {
+					return err
+				}
+			}
+		case yaml.ScalarNode:
+			if n.ShortTag() != "!!str" {
+				return nil
+			}
+			// Values that cannot be located are only an error if
+			// they are selected to be changed.
+			v, err := yamlValue(data, lines, n)
+			if err != nil {
+				v = &value{Value: Value{Value: n.Value}, err: fmt.Errorf("%s: %w", p, err)}
+			}
+			v.Path = p
+			values = append(values, v)
+		}
+		// Aliases refer to values that are already included.
+		return nil
+	}
+	if err := walk(&root, Path{}); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// lineOffsets returns the offset of the start of each line.
+func lineOffsets(data []byte) []int {
+	offsets := []int{0}
+	for i, c := range data {
+		if c == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// yamlValue finds the source of a scalar.
+func yamlValue(data []byte, lines []int, n *yaml.Node) (*value, error) {
+	if n.Line < 1 || n.Line > len(lines) {
+		return nil, fmt.Errorf("cannot locate value")
+	}
+	start := lines[n.Line-1]
+	// Columns count characters, not bytes.
+	for i := 1; i < n.Column && start < len(data); i++ {
+		_, size := utf8.DecodeRune(data[start:])
+		start += size
+	}
+	// Skip any anchor or tag, which come first.
+	for start < len(data) && (data[start] == '&' || data[start] == '!') {
+		for start < len(data) && !isYAMLSpace(data[start]) {
+			start++
+		}
+		for start < len(data) && isYAMLSpace(data[start]) {
+			start++
+		}
+	}
+	v := &value{Value: Value{Value: n.Value}, start: start}
+	switch n.Style &^ (yaml.TaggedStyle | yaml.FlowStyle) {
+	case yaml.DoubleQuotedStyle:
+		for i := start + 1; i < len(data); i++ {
+			switch data[i] {
+			case '\\':
+				i++
+			case '"':
+				v.end = i + 1
+				return v, nil
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := start + 1; i < len(data); i++ {
+			if data[i] == '\'' {
+				if i+1 < len(data) && data[i+1] == '\'' {
+					i++
+					continue
+				}
+				v.end = i + 1
+				return v, nil
+			}
+		}
+	case 0:
+		// Plain scalars can only be replaced if they are on one line.
+		if bytes.HasPrefix(data[start:], []byte(n.Value)) {
+			v.end = start + len(n.Value)
+			v.plain = true
+			return v, nil
+		}
+	default:
+		return nil, fmt.Errorf("block scalars at line %d are not supported", n.Line)
+	}
+	return nil, fmt.Errorf("cannot locate value at line %d", n.Line)
+}
+
+func isYAMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+var plainYAML = regexp.MustCompile(`^[A-Za-z0-9+/=]+$`)
+
+// quoteYAML returns s as a YAML scalar, keeping plain values plain where it is
+// safe to do so.
+func quoteYAML(v *value, s string) string {
+	if v.plain && plainYAML.MatchString(s) && readsAsString(s) {
+		return s
+	}
+	return quoteJSON(v, s)
+}
+
+// readsAsString reports whether a plain scalar would be read as a string,
+// rather than a number, boolean, or null.
+func readsAsString(s string) bool {
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(s), &n); err != nil || len(n.Content) == 0 {
+		return false
+	}
+	return n.Content[0].ShortTag() == "!!str"
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package edit supports editing configuration files that contain encrypted
+// values. A Session decrypts every value it recognizes to produce a plain text
+// view of the file, and later re-encrypts the values that were changed in that
+// view, keeping the original cipher text of the others.
+package edit
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/document"
+)
+
+// ErrUnknownFormat reports a format that is not supported.
+var ErrUnknownFormat = errors.New("unknown file format")
+
+// Format identifies a file syntax, which is either a conffile.Format or a
+// document.Format.
+type Format string
+
+// Formats lists the supported formats.
+func Formats() []Format {
+	var out []Format
+	for _, f := range conffile.Formats() {
+		out = append(out, Format(f))
+	}
+	for _, f := range document.Formats() {
+		out = append(out, Format(f))
+	}
+	return out
+}
+
+// ParseFormat returns the Format with the given name.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats() {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownFormat, s)
+}
+
+// DetectFormat guesses the format of a file from its name.
+func DetectFormat(path string) (Format, error) {
+	if f, err := conffile.DetectFormat(path); err == nil {
+		return Format(f), nil
+	}
+	if f, err := document.DetectFormat(path); err == nil {
+		return Format(f), nil
+	}
+	return "", fmt.Errorf("%w for %s", ErrUnknownFormat, path)
+}
+
+// entry is a named value.
+type entry struct {
+	name, value string
+}
+
+// file is the subset of conffile.Document and document.Document that a
+// Session needs. Entries are set by their position, since names can repeat.
+type file interface {
+	entries() []entry
+	set(i int, value string) error
+	bytes() []byte
+}
+
+type confFile struct {
+	conffile.Document
+}
+
+func (f confFile) entries() []entry {
+	var out []entry
+	for _, e := range f.Entries() {
+		out = append(out, entry{e.Name, e.Value})
+	}
+	return out
+}
+
+func (f confFile) set(i int, value string) error {
+	return f.SetEntry(i, value)
+}
+
+func (f confFile) bytes() []byte {
+	return f.Bytes()
+}
+
+type docFile struct {
+	*document.Document
+}
+
+func (f docFile) entries() []entry {
+	var out []entry
+	for _, v := range f.Values() {
+		out = append(out, entry{v.Path.String(), v.Value})
+	}
+	return out
+}
+
+func (f docFile) set(i int, value string) error {
+	return f.SetValue(i, value)
+}
+
+func (f docFile) bytes() []byte {
+	return f.Bytes()
+}
+
+func parse(format Format, data []byte) (file, error) {
+	if _, err := conffile.ParseFormat(string(format)); err == nil {
+		doc, err := conffile.Parse(conffile.Format(format), data)
+		if err != nil {
+			return nil, err
+		}
+		return confFile{doc}, nil
+	}
+	if _, err := document.ParseFormat(string(format)); err == nil {
+		doc, err := document.Parse(document.Format(format), data)
+		if err != nil {
+			return nil, err
+		}
+		return docFile{doc}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// slot identifies an entry by its name and how many entries with the same
+// name come before it, so that repeated names are told apart and entries
+// still match after others are added or removed.
+type slot struct {
+	name string
+	n    int
+}
+
+// slots returns the slot of each entry.
+func slots(entries []entry) []slot {
+	out := make([]slot, len(entries))
+	seen := map[string]int{}
+	for i, e := range entries {
+		out[i] = slot{e.name, seen[e.name]}
+		seen[e.name]++
+	}
+	return out
+}
+
+// secret is a value that was decrypted for editing.
+type secret struct {
+	ciphertext string
+	plaintext  string
+	// Whether the cipher text was wrapped in document's ENC[...] marker.
+	marked bool
+}
+
+// Session tracks the encrypted values in a file while it is edited.
+type Session struct {
+	format  Format
+	secrets map[slot]secret
+}
+
+// Open decrypts every value in data that c can decrypt, returning a Session
+// and the plain text view of the file. In JSON, YAML, and TOML documents,
+// values marked with ENC[...] must decrypt successfully.
+func Open(format Format, data []byte, c crypt.Cipher) (*Session, []byte, error) {
+	f, err := parse(format, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &Session{format: format, secrets: map[slot]secret{}}
+	entries := f.entries()
+	for i, k := range slots(entries) {
+		e := entries[i]
+		sec := secret{ciphertext: e.value}
+		ciphertext := e.value
+		if _, ok := f.(docFile); ok && document.IsMarked(e.value) {
+			sec.marked = true
+			ciphertext = document.Unmark(e.value)
+		}
+		plaintext, err := c.Decrypt(ciphertext)
+		if err != nil {
+			if sec.marked {
+				return nil, nil, fmt.Errorf("%s: %w", e.name, err)
+			}
+			continue
+		}
+		sec.plaintext = plaintext
+		if err := f.set(i, plaintext); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", e.name, err)
+		}
+		s.secrets[k] = sec
+	}
+	return s, f.bytes(), nil
+}
+
+// Secrets returns the names of the values that were decrypted, sorted. Names
+// that are repeated in the file may be listed more than once.
+func (s *Session) Secrets() []string {
+	var names []string
+	for k := range s.secrets {
+		names = append(names, k.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Seal takes the edited plain text view of the file and encrypts the values
+// that were decrypted by Open with c. Values are matched with those decrypted
+// by Open by name, and by position among values with the same name. Values
+// that are unchanged keep their original cipher text.
+//
+// So that secrets are not saved in plain text when they are moved or renamed,
+// any other value that equals the plain text of a value decrypted by Open is
+// encrypted too. Seal returns the new contents of the file and the names of
+// the values that were re-encrypted.
+func (s *Session) Seal(edited []byte, c crypt.Cipher) ([]byte, []string, error) {
+	f, err := parse(s.format, edited)
+	if err != nil {
+		return nil, nil, err
+	}
+	changed := []string{}
+	entries := f.entries()
+	for i, k := range slots(entries) {
+		e := entries[i]
+		sec, matched := s.secrets[k]
+		if !matched {
+			// A value that was moved or renamed.
+			var ok bool
+			if sec.marked, ok = s.opened(e.value); !ok {
+				continue
+			}
+		}
+		ciphertext := sec.ciphertext
+		if !matched || subtle.ConstantTimeCompare([]byte(e.value), []byte(sec.plaintext)) != 1 {
+			if ciphertext, err = c.Encrypt(e.value); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", e.name, err)
+			}
+			if sec.marked {
+				ciphertext = document.Mark(ciphertext)
+			}
+			changed = append(changed, e.name)
+		}
+		if err := f.set(i, ciphertext); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", e.name, err)
+		}
+	}
+	return f.bytes(), changed, nil
+}
+
+// opened reports whether value is the plain text of a value decrypted by
+// Open, and if so, whether any such value was marked. Empty values are never
+// secrets.
+func (s *Session) opened(value string) (marked, ok bool) {
+	if value == "" {
+		return false, false
+	}
+	for _, sec := range s.secrets {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(sec.plaintext)) == 1 {
+			marked, ok = marked || sec.marked, true
+		}
+	}
+	return marked, ok
+}
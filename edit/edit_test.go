@@ -0,0 +1,165 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package edit
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/crypttest"
+)
+
+type EditSuite struct {
+	key *crypt.Key
+}
+
+func (s *EditSuite) SetUpTest(c *check.C) {
+	s.key = crypttest.NewKey("edit")
+}
+
+func (s *EditSuite) encrypt(c *check.C, plaintext string) string {
+	ciphertext, err := s.key.Encrypt(plaintext)
+	c.Assert(err, check.IsNil)
+	return ciphertext
+}
+
+func (s *EditSuite) TestGCFG(c *check.C) {
+	password := s.encrypt(c, "hunter2")
+	token := s.encrypt(c, "token")
+	original := "[Postgres]\nURL = postgres://db\nPassword = " + password +
+		" ; comment\n\n[API]\nToken = " + token + "\n"
+	session, view, err := Open("gcfg", []byte(original), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(view), check.Equals,
+		"[Postgres]\nURL = postgres://db\nPassword = hunter2 ; comment\n\n[API]\nToken = token\n")
+	c.Check(session.Secrets(), check.DeepEquals, []string{"API.Token", "Postgres.Password"})
+
+	// Saving without changes restores the original file.
+	out, changed, err := session.Seal(view, s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(out), check.Equals, original)
+	c.Check(changed, check.HasLen, 0)
+
+	// Only changed values are re-encrypted.
+	edited := strings.Replace(string(view), "hunter2", "new password", 1)
+	edited = strings.Replace(edited, "postgres://db", "postgres://other", 1)
+	out, changed, err = session.Seal([]byte(edited), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(changed, check.DeepEquals, []string{"Postgres.Password"})
+	c.Check(strings.Contains(string(out), "new password"), check.Equals, false)
+	c.Check(strings.Contains(string(out), "URL = postgres://other\n"), check.Equals, true)
+	c.Check(strings.Contains(string(out), "Token = "+token+"\n"), check.Equals, true)
+	c.Check(strings.Contains(string(out), "; comment\n"), check.Equals, true)
+
+	_, _, err = session.Seal([]byte("Password = x\n"), s.key)
+	c.Check(err, check.ErrorMatches, `line 1: variable outside of a section`)
+}
+
+func (s *EditSuite) TestDocument(c *check.C) {
+	marked := "ENC[" + s.encrypt(c, "hunter2") + "]"
+	original := "db:\n  password: " + marked + " # secret\n  user: connect\n"
+	session, view, err := Open("yaml", []byte(original), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(view), check.Equals, "db:\n  password: hunter2 # secret\n  user: connect\n")
+
+	out, changed, err := session.Seal([]byte("db:\n  password: changed # secret\n  user: connect\n"),
+		s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(changed, check.DeepEquals, []string{".db.password"})
+	c.Check(string(out), check.Matches, `db:\n  password: "ENC\[.*\]" # secret\n  user: connect\n`)
+
+	// Marked values must decrypt.
+	_, _, err = Open("yaml", []byte(original), crypttest.NewKey("other"))
+	c.Check(err, check.ErrorMatches, `.db.password: Decryption failed`)
+}
+
+func (s *EditSuite) TestRepeatedNames(c *check.C) {
+	first, second := s.encrypt(c, "first"), s.encrypt(c, "second")
+	original := "password=" + first + "\npassword=" + second + "\n"
+	session, view, err := Open("workbench", []byte(original), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(view), check.Equals, "password=first\npassword=second\n")
+	c.Check(session.Secrets(), check.DeepEquals, []string{"password", "password"})
+
+	out, changed, err := session.Seal(view, s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(out), check.Equals, original)
+	c.Check(changed, check.HasLen, 0)
+
+	out, changed, err = session.Seal([]byte("password=first\npassword=changed\n"), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(changed, check.DeepEquals, []string{"password"})
+	c.Check(strings.HasPrefix(string(out), "password="+first+"\npassword="), check.Equals, true)
+	c.Check(strings.Contains(string(out), "changed"), check.Equals, false)
+}
+
+func (s *EditSuite) TestCorruptWorkbench(c *check.C) {
+	key := crypttest.NewWorkbenchKey("edit")
+	corrupt := crypttest.CorruptWorkbenchValue(key)
+	ciphertext, err := key.Encrypt("secret")
+	c.Assert(err, check.IsNil)
+	original := "password=" + ciphertext + "\nbroken=" + corrupt + "\n"
+	// Values that cannot be decrypted are left as they are.
+	session, view, err := Open("workbench", []byte(original), key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(view), check.Equals, "password=secret\nbroken="+corrupt+"\n")
+	out, _, err := session.Seal(view, key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(out), check.Equals, original)
+}
+
+func (s *EditSuite) TestRenamed(c *check.C) {
+	original := "DB_PASS=" + s.encrypt(c, "hunter2") + "\nUSER=connect\n"
+	session, view, err := Open("dotenv", []byte(original), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(view), check.Equals, "DB_PASS=hunter2\nUSER=connect\n")
+
+	out, changed, err := session.Seal([]byte("DB_PASSWORD=hunter2\nUSER=connect\n"), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(changed, check.DeepEquals, []string{"DB_PASSWORD"})
+	c.Check(strings.Contains(string(out), "hunter2"), check.Equals, false)
+	c.Check(string(out), check.Matches, `DB_PASSWORD=\S+\nUSER=connect\n`)
+}
+
+func (s *EditSuite) TestMoved(c *check.C) {
+	original := "users:\n  - password: ENC[" + s.encrypt(c, "hunter2") + "]\n"
+	session, view, err := Open("yaml", []byte(original), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(view), check.Equals, "users:\n  - password: hunter2\n")
+
+	// .users[0] becomes .users[1], and the new .users[0] takes its place.
+	out, changed, err := session.Seal(
+		[]byte("users:\n  - password: new\n  - password: hunter2\n"), s.key)
+	c.Assert(err, check.IsNil)
+	c.Check(changed, check.DeepEquals, []string{".users[0].password", ".users[1].password"})
+	c.Check(string(out), check.Matches,
+		`users:\n  - password: "ENC\[.*\]"\n  - password: "ENC\[.*\]"\n`)
+}
+
+func (s *EditSuite) TestFormats(c *check.C) {
+	for path, want := range map[string]Format{
+		"rstudio-connect.gcfg": "gcfg",
+		"database.conf":        "workbench",
+		".env":                 "dotenv",
+		"values.yaml":          "yaml",
+		"config.json":          "json",
+		"service.toml":         "toml",
+	} {
+		f, err := DetectFormat(path)
+		c.Check(err, check.IsNil)
+		c.Check(f, check.Equals, want)
+	}
+	_, err := DetectFormat("notes.txt")
+	c.Check(err, check.ErrorMatches, `unknown file format for notes.txt`)
+	_, err = ParseFormat("ini")
+	c.Check(err, check.ErrorMatches, `unknown file format "ini"`)
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&EditSuite{})
+	check.TestingT(t)
+}
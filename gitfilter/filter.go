@@ -0,0 +1,137 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package gitfilter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/document"
+)
+
+const (
+	// PlainPrefix and PlainSuffix surround values in the working tree that
+	// are stored encrypted in the repository. Within them, backslashes,
+	// closing brackets, and newlines are escaped with a backslash.
+	PlainPrefix = "DEC["
+	PlainSuffix = "]"
+	// FilterName is the name of the filter and diff drivers in git
+	// configuration and .gitattributes.
+	FilterName = "rskey"
+)
+
+var (
+	plainValue     = regexp.MustCompile(`DEC\[(?:[^\\\]\n]|\\.)*\]`)
+	encryptedValue = regexp.MustCompile(`ENC\[[A-Za-z0-9+/]+={0,2}\]`)
+)
+
+// Clean encrypts every DEC[...] value in data, as for a git clean filter, and
+// writes it as ENC[...]. To keep the output stable, values whose plain text is
+// unchanged reuse the cipher text of the same value in previous, which is
+// usually the copy of the file in the index.
+func Clean(data, previous []byte, c crypt.Cipher) ([]byte, error) {
+	var candidates []string
+	for _, match := range encryptedValue.FindAll(previous, -1) {
+		candidates = append(candidates, document.Unmark(string(match)))
+	}
+	var err error
+	out := plainValue.ReplaceAllFunc(data, func(match []byte) []byte {
+		if err != nil {
+			return match
+		}
+		s := string(match)
+		var ciphertext string
+		ciphertext, err = crypt.EncryptStable(c, unescape(s[len(PlainPrefix):len(s)-len(PlainSuffix)]), candidates...)
+		return []byte(document.Mark(ciphertext))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Smudge decrypts every ENC[...] value in data, as for a git smudge filter,
+// and writes it as DEC[...]. Values that c cannot decrypt are left alone and
+// counted in the result, so that a checkout never fails for want of a key.
+func Smudge(data []byte, c crypt.Cipher) ([]byte, int) {
+	failed := 0
+	out := encryptedValue.ReplaceAllFunc(data, func(match []byte) []byte {
+		text, err := c.Decrypt(document.Unmark(string(match)))
+		if err != nil {
+			failed++
+			return match
+		}
+		return []byte(PlainPrefix + escape(text) + PlainSuffix)
+	})
+	return out, failed
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `]`, `\]`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// AddAttributes appends a line assigning the rskey filter and diff drivers to
+// each pattern in the .gitattributes file at path, creating it if necessary.
+// Patterns that already have the filter are skipped. It returns the patterns
+// that were added.
+func AddAttributes(path string, patterns []string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	existing := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			if attr == "filter="+FilterName {
+				existing[fields[0]] = true
+			}
+		}
+	}
+	var buf bytes.Buffer
+	buf.Write(data)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	var added []string
+	for _, p := range patterns {
+		if existing[p] {
+			continue
+		}
+		existing[p] = true
+		fmt.Fprintf(&buf, "%s filter=%s diff=%s\n", p, FilterName, FilterName)
+		added = append(added, p)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	return added, conffile.WriteFile(path, buf.Bytes(), conffile.ExistingPerm(path, 0644))
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package gitfilter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypttest"
+)
+
+func (s *GitFilterSuite) TestCleanSmudge(c *check.C) {
+	key := crypttest.NewKey("filter")
+	plain := "[Postgres]\nPassword = DEC[hunter2]\nToken = DEC[a\\]b\\\\c\\nd]\nURL = postgres://db\n"
+	clean, err := Clean([]byte(plain), nil, key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(clean), check.Matches,
+		`\[Postgres\]\nPassword = ENC\[[A-Za-z0-9+/=]+\]\nToken = ENC\[[A-Za-z0-9+/=]+\]\nURL = postgres://db\n`)
+	token := encryptedValue.FindAllString(string(clean), -1)[1]
+	text, err := key.Decrypt(token[4 : len(token)-1])
+	c.Assert(err, check.IsNil)
+	c.Check(text, check.Equals, "a]b\\c\nd")
+
+	// Smudging restores the original, and cleaning that again reuses the
+	// previous cipher text.
+	smudged, failed := Smudge(clean, key)
+	c.Check(failed, check.Equals, 0)
+	c.Check(string(smudged), check.Equals, plain)
+	again, err := Clean(smudged, clean, key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(again), check.Equals, string(clean))
+
+	// Changed values are encrypted again.
+	changed, err := Clean([]byte(strings.Replace(plain, "hunter2", "hunter3", 1)), clean, key)
+	c.Assert(err, check.IsNil)
+	c.Check(string(changed), check.Not(check.Equals), string(clean))
+	c.Check(strings.Contains(string(changed), token), check.Equals, true)
+
+	// Diffs look the same either way.
+	c.Check(string(Textconv(smudged, key, TextconvOptions{})), check.Equals,
+		string(Textconv(clean, key, TextconvOptions{})))
+
+	// Values encrypted with another key are left alone.
+	smudged, failed = Smudge(clean, crypttest.NewKey("other"))
+	c.Check(failed, check.Equals, 2)
+	c.Check(string(smudged), check.Equals, string(clean))
+}
+
+func (s *GitFilterSuite) TestAddAttributes(c *check.C) {
+	path := filepath.Join(c.MkDir(), ".gitattributes")
+	added, err := AddAttributes(path, []string{"*.gcfg", "config.yml"})
+	c.Assert(err, check.IsNil)
+	c.Check(added, check.DeepEquals, []string{"*.gcfg", "config.yml"})
+
+	c.Assert(os.WriteFile(path, []byte("*.gcfg filter=rskey diff=rskey\n*.png binary"), 0644), check.IsNil)
+	c.Assert(os.Chmod(path, 0664), check.IsNil)
+	added, err = AddAttributes(path, []string{"*.gcfg", "*.env"})
+	c.Assert(err, check.IsNil)
+	c.Check(added, check.DeepEquals, []string{"*.env"})
+	// The existing permissions are kept.
+	info, err := os.Stat(path)
+	c.Assert(err, check.IsNil)
+	c.Check(info.Mode().Perm(), check.Equals, os.FileMode(0664))
+	data, err := os.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Check(string(data), check.Equals,
+		"*.gcfg filter=rskey diff=rskey\n*.png binary\n*.env filter=rskey diff=rskey\n")
+
+	added, err = AddAttributes(path, []string{"*.env"})
+	c.Assert(err, check.IsNil)
+	c.Check(added, check.HasLen, 0)
+}
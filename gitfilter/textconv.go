@@ -0,0 +1,131 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gitfilter implements git attribute drivers for files that contain
+// encrypted values, so that they can be reviewed and edited as plain text
+// while the repository stores only cipher text.
+package gitfilter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/workbench"
+)
+
+// candidate matches text that might be cipher text, optionally wrapped in an
+// ENC[...] marker, or a DEC[...] value that the clean filter will encrypt. The
+// shortest cipher text any supported mode produces is 40 characters of base64.
+var candidate = regexp.MustCompile(plainValue.String() + `|ENC\[[A-Za-z0-9+/]+={0,2}\]|[A-Za-z0-9+/]{40,}={0,2}`)
+
+// TextconvOptions configure Textconv.
+type TextconvOptions struct {
+	// Plaintext shows decrypted values rather than a hash of them.
+	Plaintext bool
+}
+
+// Textconv returns data with every value c can decrypt replaced by a hash of
+// its plain text and the fingerprint of the key that decrypted it, which is
+// suitable for use as a git diff textconv driver. Hashes are keyed with c's
+// active key, so they are stable across encryptions and key rotations but
+// cannot be used to guess secrets without the key. Workbench values that c
+// cannot decrypt are labelled with the checksum of their key; anything else
+// is left alone.
+//
+// DEC[...] values, which git shows in place of encrypted values when the
+// rskey filter is in use, are treated as if they were encrypted with the
+// active key.
+func Textconv(data []byte, c crypt.Cipher, opts TextconvOptions) []byte {
+	h := newHasher(c)
+	return candidate.ReplaceAllFunc(data, func(match []byte) []byte {
+		s := string(match)
+		if plainValue.Match(match) {
+			return h.label(unescape(s[len(PlainPrefix):len(s)-len(PlainSuffix)]), c, opts)
+		}
+		if len(s) > len("ENC[]") && s[:4] == "ENC[" {
+			s = s[4 : len(s)-1]
+		}
+		text, key, err := decryptKey(c, s)
+		if err != nil {
+			if sum, err := workbench.Checksum(s); err == nil && workbench.IsEncrypted(s) {
+				return []byte("[encrypted key:" + sum + ", cannot decrypt]")
+			}
+			return match
+		}
+		return h.label(text, key, opts)
+	})
+}
+
+// decryptKey decrypts s, returning the key that succeeded. Keyrings report
+// which of their keys it was.
+func decryptKey(c crypt.Cipher, s string) (string, crypt.Cipher, error) {
+	if ring, ok := c.(*crypt.Keyring); ok {
+		text, key, err := ring.DecryptKey(s)
+		if err != nil {
+			return "", nil, err
+		}
+		// Keyrings can contain other keyrings.
+		if _, ok := key.(*crypt.Keyring); ok {
+			return decryptKey(key, s)
+		}
+		return text, key, nil
+	}
+	text, err := c.Decrypt(s)
+	return text, c, err
+}
+
+// shortFingerprint abbreviates a key's fingerprint for display.
+func shortFingerprint(c crypt.Cipher) string {
+	fp := c.Fingerprint()
+	if len(fp) > 16 {
+		fp = fp[:16]
+	}
+	return fp
+}
+
+// hasher computes keyed hashes of plain text.
+type hasher struct {
+	key []byte
+}
+
+// newHasher derives a hash key from c's active key material. Keys that cannot
+// be marshalled fall back to their fingerprint, which keeps hashes stable but
+// not secret.
+func newHasher(c crypt.Cipher) hasher {
+	for {
+		ring, ok := c.(*crypt.Keyring)
+		if !ok {
+			break
+		}
+		c = ring.Active()
+	}
+	material := []byte(c.Fingerprint())
+	if m, ok := c.(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			material = text
+		}
+	}
+	mac := hmac.New(sha256.New, []byte("rskey git-diff-textconv"))
+	mac.Write(material)
+	return hasher{key: mac.Sum(nil)}
+}
+
+// label describes a value decrypted with key.
+func (h hasher) label(text string, key crypt.Cipher, opts TextconvOptions) []byte {
+	if opts.Plaintext {
+		return []byte("[decrypted " + strconv.Quote(text) + " key:" + shortFingerprint(key) + "]")
+	}
+	return []byte("[decrypted sha256:" + h.sum(text) + " key:" + shortFingerprint(key) + "]")
+}
+
+// sum returns an abbreviated hash of s.
+func (h hasher) sum(s string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))[:12]
+}
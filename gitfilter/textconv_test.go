@@ -0,0 +1,69 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package gitfilter
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/crypttest"
+)
+
+type GitFilterSuite struct{}
+
+func (s *GitFilterSuite) TestTextconv(c *check.C) {
+	key := crypttest.NewKey("textconv")
+	fp := key.Fingerprint()[:16]
+	input := "[Postgres]\nURL = postgres://db\nPassword = " + crypttest.Encrypt(c, key, "hunter2") +
+		"\nToken: ENC[" + crypttest.Encrypt(c, key, "token") + "]\n" +
+		"Other = " + crypttest.Encrypt(c, crypttest.NewKey("other"), "unknown") + "\n"
+	out := string(Textconv([]byte(input), key, TextconvOptions{}))
+	c.Check(out, check.Matches, `(?s)\[Postgres\]\nURL = postgres://db\n`+
+		`Password = \[decrypted sha256:[0-9a-f]{12} key:`+fp+`\]\n`+
+		`Token: \[decrypted sha256:[0-9a-f]{12} key:`+fp+`\]\n`+
+		`Other = [A-Za-z0-9+/=]{40,}\n`)
+
+	// Hashes are stable across encryptions, but not across keys.
+	again := string(Textconv([]byte("Password = "+crypttest.Encrypt(c, key, "hunter2")), key, TextconvOptions{}))
+	hash := regexp.MustCompile(`sha256:[0-9a-f]+`)
+	c.Check(hash.FindString(again), check.Equals, hash.FindString(out))
+	other := crypttest.NewKey("other")
+	c.Check(hash.FindString(string(Textconv([]byte(crypttest.Encrypt(c, other, "hunter2")), other, TextconvOptions{}))),
+		check.Not(check.Equals), hash.FindString(out))
+
+	out = string(Textconv([]byte(input), key, TextconvOptions{Plaintext: true}))
+	c.Check(strings.Contains(out, `Password = [decrypted "hunter2" key:`+fp+"]\n"), check.Equals, true)
+	c.Check(strings.Contains(out, `Token: [decrypted "token" key:`+fp+"]\n"), check.Equals, true)
+}
+
+func (s *GitFilterSuite) TestTextconvKeyring(c *check.C) {
+	k1 := crypttest.NewKey("first")
+	k2 := crypttest.NewKey("second")
+	ring := crypt.NewKeyring(k2, crypt.KeyringOptions{})
+	ring.AddDecryptOnly(k1)
+	out := string(Textconv([]byte(crypttest.Encrypt(c, k1, "old")+"\n"+crypttest.Encrypt(c, k2, "new")), ring,
+		TextconvOptions{Plaintext: true}))
+	c.Check(out, check.Equals, `[decrypted "old" key:`+k1.Fingerprint()[:16]+"]\n"+
+		`[decrypted "new" key:`+k2.Fingerprint()[:16]+"]")
+}
+
+func (s *GitFilterSuite) TestTextconvWorkbench(c *check.C) {
+	key := crypttest.NewWorkbenchKey("textconv")
+	other := crypttest.NewWorkbenchKey("other")
+	input := "password=" + crypttest.Encrypt(c, key, "secret") + "\nother=" + crypttest.Encrypt(c, other, "secret") + "\n" +
+		"corrupt=" + crypttest.CorruptWorkbenchValue(key) + "\n"
+	out := string(Textconv([]byte(input), key, TextconvOptions{Plaintext: true}))
+	c.Check(out, check.Equals, `password=[decrypted "secret" key:`+key.Fingerprint()+"]\n"+
+		"other=[encrypted key:"+other.Fingerprint()+", cannot decrypt]\n"+
+		"corrupt=[encrypted key:"+key.Fingerprint()+", cannot decrypt]\n")
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&GitFilterSuite{})
+	check.TestingT(t)
+}
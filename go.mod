@@ -1,28 +1,41 @@
 module github.com/rstudio/rskey
 
-go 1.24
+go 1.24.0
 
 require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/lib/pq v1.12.3
 	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.7
 	golang.org/x/crypto v0.40.0
 	golang.org/x/term v0.33.0
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c
+	gopkg.in/yaml.v3 v3.0.1
+	modernc.org/sqlite v1.46.1
 )
 
 require (
 	github.com/bmatcuk/doublestar/v4 v4.0.2 // indirect
 	github.com/cyphar/filepath-securejoin v0.2.5 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/google/addlicense v1.1.1 // indirect
 	github.com/google/licenseclassifier v0.0.0-20200402202327-879cb1424de0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/kr/pretty v0.2.1 // indirect
 	github.com/kr/text v0.1.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v1.0.0 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
 	github.com/sergi/go-diff v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.7 // indirect
 	go.elastic.co/go-licence-detector v0.7.0 // indirect
-	golang.org/x/mod v0.20.0 // indirect
-	golang.org/x/sync v0.8.0 // indirect
-	golang.org/x/sys v0.34.0 // indirect
+	golang.org/x/exp v0.0.0-20251023183803-a4bb9ffd2546 // indirect
+	golang.org/x/mod v0.29.0 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+	golang.org/x/sys v0.37.0 // indirect
+	modernc.org/libc v1.67.6 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.11.0 // indirect
 )
 
 tool (
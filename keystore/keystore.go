@@ -0,0 +1,310 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package keystore manages a directory of labelled keys, each with a lifecycle
+// state that controls whether it may be used for encryption, decryption, or
+// neither.
+package keystore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+)
+
+// State is the lifecycle state of a key.
+type State string
+
+const (
+	// Active keys are used for both encryption and decryption.
+	Active State = "active"
+	// DecryptOnly keys are only used for decryption.
+	DecryptOnly State = "decrypt-only"
+	// Retired keys are never used, but are kept until removed.
+	Retired State = "retired"
+)
+
+var (
+	// ErrNotFound reports a label that is not in the keystore.
+	ErrNotFound = errors.New("no key with this label")
+	// ErrExists reports an attempt to add a label that is already in use.
+	ErrExists = errors.New("a key with this label already exists")
+	// ErrNotActive reports an attempt to encrypt with a key that is not
+	// active.
+	ErrNotActive = errors.New("only active keys can be used for encryption")
+	// ErrRetired reports an attempt to use a retired key.
+	ErrRetired = errors.New("retired keys cannot be used")
+)
+
+var validLabel = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ParseState returns the State with the given name.
+func ParseState(s string) (State, error) {
+	switch State(s) {
+	case Active, DecryptOnly, Retired:
+		return State(s), nil
+	}
+	return "", fmt.Errorf("unknown key state %q", s)
+}
+
+// Entry describes a key in the keystore.
+type Entry struct {
+	Label       string    `json:"label"`
+	Mode        string    `json:"mode"`
+	State       State     `json:"state"`
+	Created     time.Time `json:"created"`
+	Fingerprint string    `json:"fingerprint"`
+}
+
+// Store is a keystore directory. Each key is kept in two files: LABEL.key,
+// which holds the key exactly as it would be written for the product, and
+// LABEL.json, which holds its Entry.
+type Store struct {
+	dir string
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// DefaultDir returns the default keystore directory, which is
+// $XDG_CONFIG_HOME/rskey/keys or ~/.config/rskey/keys.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "rskey", "keys"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "rskey", "keys"), nil
+}
+
+// Open returns the keystore in the given directory, creating it if
+// necessary.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, now: time.Now}, nil
+}
+
+// Dir returns the keystore's directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) path(label, ext string) string {
+	return filepath.Join(s.dir, label+ext)
+}
+
+// Add stores a key under the given label. The key must be loadable in the
+// given mode. Adding an active key demotes any other active key with the same
+// mode to decrypt-only, so that there is only ever one key to encrypt with.
+func (s *Store) Add(label, mode string, key []byte, state State) (*Entry, error) {
+	if !validLabel.MatchString(label) {
+		return nil, fmt.Errorf("invalid label %q", label)
+	}
+	if _, err := ParseState(string(state)); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(s.path(label, ".json")); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrExists, label)
+	}
+	c, err := crypt.LoadCipher(mode, bytes.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+	entry := &Entry{
+		Label:       label,
+		Mode:        mode,
+		State:       state,
+		Created:     s.now().UTC(),
+		Fingerprint: c.Fingerprint(),
+	}
+	if state == Active {
+		entries, err := s.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.State == Active && e.Mode == mode {
+				e.State = DecryptOnly
+				if err := s.writeEntry(&e); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := conffile.WriteFile(s.path(label, ".key"), key, 0600); err != nil {
+		return nil, err
+	}
+	if err := s.writeEntry(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *Store) writeEntry(e *Entry) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return conffile.WriteFile(s.path(e.Label, ".json"), append(data, '\n'), 0600)
+}
+
+// Get returns the entry for a label.
+func (s *Store) Get(label string) (*Entry, error) {
+	if !validLabel.MatchString(label) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, label)
+	}
+	data, err := os.ReadFile(s.path(label, ".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, label)
+	} else if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("invalid keystore entry %s: %v", label, err)
+	}
+	return &e, nil
+}
+
+// List returns every entry, oldest first.
+func (s *Store) List() ([]Entry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	out := []Entry{}
+	for _, f := range files {
+		label, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() || !validLabel.MatchString(label) {
+			continue
+		}
+		e, err := s.Get(label)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Created.Before(out[j].Created)
+	})
+	return out, nil
+}
+
+// SetState changes the lifecycle state of a key. Activating a key demotes
+// other active keys with the same mode, as in Add().
+func (s *Store) SetState(label string, state State) error {
+	if _, err := ParseState(string(state)); err != nil {
+		return err
+	}
+	e, err := s.Get(label)
+	if err != nil {
+		return err
+	}
+	if state == Active {
+		entries, err := s.List()
+		if err != nil {
+			return err
+		}
+		for _, other := range entries {
+			if other.State == Active && other.Mode == e.Mode && other.Label != label {
+				other.State = DecryptOnly
+				if err := s.writeEntry(&other); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	e.State = state
+	return s.writeEntry(e)
+}
+
+// Remove deletes a key and its entry.
+func (s *Store) Remove(label string) error {
+	if _, err := s.Get(label); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(label, ".key")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(s.path(label, ".json"))
+}
+
+// KeyData returns the stored key for a label.
+func (s *Store) KeyData(label string) ([]byte, error) {
+	if _, err := s.Get(label); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(s.path(label, ".key"))
+}
+
+// Cipher loads the key for a label in the given mode, or the mode it was added
+// with if mode is empty. Retired keys cannot be loaded.
+func (s *Store) Cipher(label, mode string) (crypt.Cipher, error) {
+	e, err := s.Get(label)
+	if err != nil {
+		return nil, err
+	}
+	if e.State == Retired {
+		return nil, fmt.Errorf("%w: %s", ErrRetired, label)
+	}
+	if mode == "" {
+		mode = e.Mode
+	}
+	data, err := s.KeyData(label)
+	if err != nil {
+		return nil, err
+	}
+	return crypt.LoadCipher(mode, bytes.NewReader(data))
+}
+
+// EncryptCipher is like Cipher, but fails unless the key is active.
+func (s *Store) EncryptCipher(label, mode string) (crypt.Cipher, error) {
+	e, err := s.Get(label)
+	if err != nil {
+		return nil, err
+	}
+	if e.State != Active {
+		return nil, fmt.Errorf("%w: %s is %s", ErrNotActive, label, e.State)
+	}
+	return s.Cipher(label, mode)
+}
+
+// DecryptCipher returns a Keyring that decrypts with the key for a label and
+// falls back to every other key that is not retired, most recent first. It
+// fails if the key for the label is retired.
+func (s *Store) DecryptCipher(label, mode string) (*crypt.Keyring, error) {
+	primary, err := s.Cipher(label, mode)
+	if err != nil {
+		return nil, err
+	}
+	ring := crypt.NewKeyring(primary, crypt.KeyringOptions{})
+	entries, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Label == label || e.State == Retired {
+			continue
+		}
+		c, err := s.Cipher(e.Label, "")
+		if err != nil {
+			return nil, err
+		}
+		ring.AddDecryptOnly(c)
+	}
+	return ring, nil
+}
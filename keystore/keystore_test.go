@@ -0,0 +1,189 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package keystore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/crypttest"
+	_ "github.com/rstudio/rskey/workbench"
+)
+
+type StoreSuite struct {
+	store *Store
+	clock time.Time
+}
+
+func (s *StoreSuite) SetUpTest(c *check.C) {
+	store, err := Open(filepath.Join(c.MkDir(), "keys"))
+	c.Assert(err, check.IsNil)
+	s.clock = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		s.clock = s.clock.Add(time.Hour)
+		return s.clock
+	}
+	s.store = store
+}
+
+func (s *StoreSuite) add(c *check.C, label, mode, seed string, state State) *Entry {
+	data := []byte(crypttest.NewKey(seed).HexString())
+	if mode == "workbench" {
+		data = crypttest.WorkbenchKeyData(seed)
+	}
+	e, err := s.store.Add(label, mode, data, state)
+	c.Assert(err, check.IsNil)
+	return e
+}
+
+func (s *StoreSuite) state(c *check.C, label string) State {
+	e, err := s.store.Get(label)
+	c.Assert(err, check.IsNil)
+	return e.State
+}
+
+func (s *StoreSuite) checkDecrypts(c *check.C, ring crypt.Cipher, ciphertext, want string) {
+	got, err := ring.Decrypt(ciphertext)
+	c.Check(err, check.IsNil)
+	c.Check(got, check.Equals, want)
+}
+
+func (s *StoreSuite) TestAdd(c *check.C) {
+	e := s.add(c, "one", "default", "one", Active)
+	c.Check(e.Fingerprint, check.Equals, crypttest.NewKey("one").Fingerprint())
+	c.Check(e.Created, check.Equals, time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC))
+
+	info, err := os.Stat(filepath.Join(s.store.Dir(), "one.key"))
+	c.Assert(err, check.IsNil)
+	c.Check(info.Mode().Perm(), check.Equals, os.FileMode(0600))
+	data, err := s.store.KeyData("one")
+	c.Assert(err, check.IsNil)
+	c.Check(string(data), check.Equals, crypttest.NewKey("one").HexString())
+
+	_, err = s.store.Add("one", "default", data, Active)
+	c.Check(errors.Is(err, ErrExists), check.Equals, true)
+	_, err = s.store.Add("../escape", "default", data, Active)
+	c.Check(err, check.ErrorMatches, `invalid label .*`)
+	_, err = s.store.Add("bad", "default", []byte("short"), Active)
+	c.Check(err, check.NotNil)
+	_, err = s.store.Add("bad", "unknown", data, Active)
+	c.Check(err, check.NotNil)
+	_, err = s.store.Add("bad", "default", data, "unknown")
+	c.Check(err, check.ErrorMatches, `unknown key state "unknown"`)
+}
+
+func (s *StoreSuite) TestActiveDemotion(c *check.C) {
+	s.add(c, "one", "default", "one", Active)
+	s.add(c, "wb", "workbench", "wb", Active)
+	s.add(c, "two", "default", "two", Active)
+	c.Check(s.state(c, "one"), check.Equals, DecryptOnly)
+	c.Check(s.state(c, "wb"), check.Equals, Active)
+	c.Check(s.state(c, "two"), check.Equals, Active)
+
+	c.Assert(s.store.SetState("one", Active), check.IsNil)
+	c.Check(s.state(c, "one"), check.Equals, Active)
+	c.Check(s.state(c, "two"), check.Equals, DecryptOnly)
+
+	entries, err := s.store.List()
+	c.Assert(err, check.IsNil)
+	labels := []string{}
+	for _, e := range entries {
+		labels = append(labels, e.Label)
+	}
+	c.Check(labels, check.DeepEquals, []string{"one", "wb", "two"})
+}
+
+func (s *StoreSuite) TestEncryptCipher(c *check.C) {
+	s.add(c, "old", "default", "old", Active)
+	s.add(c, "new", "default", "new", Active)
+
+	_, err := s.store.EncryptCipher("old", "")
+	c.Check(errors.Is(err, ErrNotActive), check.Equals, true)
+	enc, err := s.store.EncryptCipher("new", "")
+	c.Assert(err, check.IsNil)
+	c.Check(enc.Fingerprint(), check.Equals, crypttest.NewKey("new").Fingerprint())
+
+	fips, err := s.store.EncryptCipher("new", "fips")
+	c.Assert(err, check.IsNil)
+	c.Check(fips.Mode(), check.Equals, "fips")
+
+	_, err = s.store.EncryptCipher("missing", "")
+	c.Check(errors.Is(err, ErrNotFound), check.Equals, true)
+}
+
+func (s *StoreSuite) TestDecryptCipher(c *check.C) {
+	s.add(c, "old", "default", "old", Active)
+	s.add(c, "wb", "workbench", "wb", Active)
+	s.add(c, "new", "default", "new", Active)
+	s.add(c, "gone", "default", "gone", Retired)
+
+	oldCT, err := crypttest.NewKey("old").Encrypt("old secret")
+	c.Assert(err, check.IsNil)
+	wbCT, err := crypttest.NewWorkbenchKey("wb").Encrypt("wb secret")
+	c.Assert(err, check.IsNil)
+	goneCT, err := crypttest.NewKey("gone").Encrypt("gone secret")
+	c.Assert(err, check.IsNil)
+
+	ring, err := s.store.DecryptCipher("new", "")
+	c.Assert(err, check.IsNil)
+	c.Check(ring.Fingerprint(), check.Equals, crypttest.NewKey("new").Fingerprint())
+	s.checkDecrypts(c, ring, oldCT, "old secret")
+	s.checkDecrypts(c, ring, wbCT, "wb secret")
+	_, err = ring.Decrypt(goneCT)
+	c.Check(err, check.NotNil)
+	// Fallbacks are tried most recent first.
+	c.Check(ring.Fingerprints(), check.DeepEquals, []string{
+		crypttest.NewKey("new").Fingerprint(),
+		crypttest.NewWorkbenchKey("wb").Fingerprint(),
+		crypttest.NewKey("old").Fingerprint(),
+	})
+
+	// Decrypt-only keys can be used directly.
+	ring, err = s.store.DecryptCipher("old", "")
+	c.Assert(err, check.IsNil)
+	s.checkDecrypts(c, ring, oldCT, "old secret")
+
+	_, err = s.store.DecryptCipher("gone", "")
+	c.Check(errors.Is(err, ErrRetired), check.Equals, true)
+}
+
+func (s *StoreSuite) TestRemove(c *check.C) {
+	s.add(c, "one", "default", "one", Retired)
+	c.Assert(s.store.Remove("one"), check.IsNil)
+	_, err := s.store.Get("one")
+	c.Check(errors.Is(err, ErrNotFound), check.Equals, true)
+	_, err = os.Stat(filepath.Join(s.store.Dir(), "one.key"))
+	c.Check(os.IsNotExist(err), check.Equals, true)
+	c.Check(errors.Is(s.store.Remove("one"), ErrNotFound), check.Equals, true)
+
+	entries, err := s.store.List()
+	c.Assert(err, check.IsNil)
+	c.Check(entries, check.HasLen, 0)
+}
+
+func (s *StoreSuite) TestDefaultDir(c *check.C) {
+	old, ok := os.LookupEnv("XDG_CONFIG_HOME")
+	defer func() {
+		if ok {
+			os.Setenv("XDG_CONFIG_HOME", old)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+	}()
+	os.Setenv("XDG_CONFIG_HOME", "/config")
+	dir, err := DefaultDir()
+	c.Assert(err, check.IsNil)
+	c.Check(dir, check.Equals, "/config/rskey/keys")
+}
+
+func Test(t *testing.T) {
+	check.Suite(&StoreSuite{})
+	check.TestingT(t)
+}
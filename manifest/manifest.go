@@ -0,0 +1,198 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package manifest reconciles product configuration files with a declarative
+// description of the secrets they should contain, in the manner of Terraform.
+//
+// A manifest is a YAML file such as:
+//
+//	version: 1
+//	keys:
+//	  connect:
+//	    product: connect
+//	    product_version: 2024.05.0
+//	files:
+//	  - path: /etc/rstudio-connect/rstudio-connect.gcfg
+//	    key: connect
+//	    settings:
+//	      - name: Postgres.Password
+//	        env: CONNECT_DB_PASSWORD
+//	      - name: OAuth2.ClientSecret
+//	        file: /run/secrets/oauth-client-secret
+//
+// Relative paths are relative to the directory containing the manifest.
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rstudio/rskey/conffile"
+)
+
+// ErrInvalid reports a manifest that is not valid.
+var ErrInvalid = errors.New("invalid manifest")
+
+// Manifest describes the secrets that configuration files should contain.
+type Manifest struct {
+	// Version is the manifest format version, which must be 1.
+	Version int `yaml:"version"`
+	// Keys are the keys used to encrypt settings, by name.
+	Keys map[string]KeySpec `yaml:"keys"`
+	// Files are the configuration files to manage.
+	Files []FileSpec `yaml:"files"`
+}
+
+// KeySpec describes a key, in the same way as the global command line flags.
+type KeySpec struct {
+	// Keyfile is the path to a key file.
+	Keyfile string `yaml:"keyfile"`
+	// Key is the label of a key in the keystore.
+	Key string `yaml:"key"`
+	// Mode is the encryption mode.
+	Mode string `yaml:"mode"`
+	// Product is the product the key belongs to, which provides a default
+	// key file and mode.
+	Product string `yaml:"product"`
+	// ProductVersion is the version of Product.
+	ProductVersion string `yaml:"product_version"`
+}
+
+// FileSpec describes a configuration file.
+type FileSpec struct {
+	// Path is the path to the file, which is created if it does not exist.
+	Path string `yaml:"path"`
+	// Format is the file's format. It is detected from Path by default.
+	Format conffile.Format `yaml:"format"`
+	// Key is the name of the key in Manifest.Keys used for the file.
+	Key string `yaml:"key"`
+	// Settings are the encrypted settings the file should contain.
+	Settings []Setting `yaml:"settings"`
+}
+
+// Setting is an encrypted setting and the source of its plain text. Exactly
+// one source must be given.
+type Setting struct {
+	// Name is the setting's name, as accepted by conffile.Document.
+	Name string `yaml:"name"`
+	// Env is the name of an environment variable.
+	Env string `yaml:"env"`
+	// File is the path to a file, whose trailing newline is removed.
+	File string `yaml:"file"`
+}
+
+// Load parses a manifest and checks that it is valid. Relative paths are
+// resolved against dir.
+func Load(r io.Reader, dir string) (*Manifest, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	m := &Manifest{}
+	if err := dec.Decode(m); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the manifest is empty", ErrInvalid)
+		}
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(typeErr.Errors, "; "))
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	m.resolve(dir)
+	return m, nil
+}
+
+// LoadFile reads a manifest from a file, resolving relative paths against its
+// directory.
+func LoadFile(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Load(bytes.NewReader(data), filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+func (m *Manifest) validate() error {
+	if m.Version != 1 {
+		return fmt.Errorf("unsupported version %d", m.Version)
+	}
+	for name, k := range m.Keys {
+		if k.Keyfile != "" && k.Key != "" {
+			return fmt.Errorf("key %s: only one of keyfile or key can be provided", name)
+		}
+		if k.Keyfile == "" && k.Key == "" && k.Product == "" {
+			return fmt.Errorf("key %s: one of keyfile, key, or product must be provided", name)
+		}
+	}
+	paths := map[string]bool{}
+	for i, f := range m.Files {
+		if f.Path == "" {
+			return fmt.Errorf("file %d: path is missing", i+1)
+		}
+		if paths[f.Path] {
+			return fmt.Errorf("file %s: listed more than once", f.Path)
+		}
+		paths[f.Path] = true
+		if f.Format != "" {
+			if _, err := conffile.ParseFormat(string(f.Format)); err != nil {
+				return fmt.Errorf("file %s: %v", f.Path, err)
+			}
+		} else if _, err := conffile.DetectFormat(f.Path); err != nil {
+			return fmt.Errorf("file %s: format is missing and %v", f.Path, err)
+		}
+		if _, ok := m.Keys[f.Key]; !ok {
+			return fmt.Errorf("file %s: unknown key %q", f.Path, f.Key)
+		}
+		names := map[string]bool{}
+		for j, s := range f.Settings {
+			if s.Name == "" {
+				return fmt.Errorf("file %s: setting %d: name is missing", f.Path, j+1)
+			}
+			if names[s.Name] {
+				return fmt.Errorf("file %s: setting %s is listed more than once", f.Path, s.Name)
+			}
+			names[s.Name] = true
+			if (s.Env == "") == (s.File == "") {
+				return fmt.Errorf("file %s: setting %s: exactly one of env or file must be provided",
+					f.Path, s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manifest) resolve(dir string) {
+	abs := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+	for name, k := range m.Keys {
+		k.Keyfile = abs(k.Keyfile)
+		m.Keys[name] = k
+	}
+	for i := range m.Files {
+		f := &m.Files[i]
+		f.Path = abs(f.Path)
+		if f.Format == "" {
+			f.Format, _ = conffile.DetectFormat(f.Path)
+		}
+		for j := range f.Settings {
+			f.Settings[j].File = abs(f.Settings[j].File)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/crypttest"
+)
+
+type ManifestSuite struct {
+	dir     string
+	key     *crypt.Key
+	env     map[string]string
+	planner *Planner
+}
+
+func (s *ManifestSuite) SetUpTest(c *check.C) {
+	s.dir = c.MkDir()
+	s.key = crypttest.NewKey("manifest")
+	s.env = map[string]string{"DB_PASSWORD": "hunter2", "TOKEN": "token"}
+	s.planner = &Planner{
+		Cipher: func(name string, spec KeySpec) (crypt.Cipher, error) {
+			if spec.Mode == "fips" {
+				return s.key.FIPSCipher(), nil
+			}
+			return s.key, nil
+		},
+		LookupEnv: func(name string) (string, bool) {
+			v, ok := s.env[name]
+			return v, ok
+		},
+	}
+}
+
+const example = `version: 1
+keys:
+  connect:
+    keyfile: secret.key
+files:
+  - path: rstudio-connect.gcfg
+    key: connect
+    settings:
+      - name: Postgres.Password
+        env: DB_PASSWORD
+      - name: OAuth2.ClientSecret
+        file: secrets/client-secret
+  - path: database.conf
+    key: connect
+    settings:
+      - name: password
+        env: TOKEN
+`
+
+func (s *ManifestSuite) load(c *check.C, text string) *Manifest {
+	m, err := Load(strings.NewReader(text), s.dir)
+	c.Assert(err, check.IsNil)
+	return m
+}
+
+func (s *ManifestSuite) write(c *check.C, name, text string) string {
+	path := filepath.Join(s.dir, name)
+	c.Assert(os.MkdirAll(filepath.Dir(path), 0700), check.IsNil)
+	c.Assert(os.WriteFile(path, []byte(text), 0640), check.IsNil)
+	return path
+}
+
+func (s *ManifestSuite) get(c *check.C, name, setting string) string {
+	doc, err := conffile.ReadFile(filepath.Join(s.dir, name), "")
+	c.Assert(err, check.IsNil)
+	value, ok := doc.Get(setting)
+	c.Assert(ok, check.Equals, true)
+	text, err := s.key.Decrypt(value)
+	c.Assert(err, check.IsNil)
+	return text
+}
+
+func (s *ManifestSuite) TestLoad(c *check.C) {
+	m := s.load(c, example)
+	c.Check(m.Keys["connect"].Keyfile, check.Equals, filepath.Join(s.dir, "secret.key"))
+	c.Check(m.Files[0].Path, check.Equals, filepath.Join(s.dir, "rstudio-connect.gcfg"))
+	c.Check(m.Files[0].Format, check.Equals, conffile.GCFG)
+	c.Check(m.Files[0].Settings[1].File, check.Equals,
+		filepath.Join(s.dir, "secrets/client-secret"))
+	c.Check(m.Files[1].Format, check.Equals, conffile.Workbench)
+
+	for text, want := range map[string]string{
+		"":                       `invalid manifest: the manifest is empty`,
+		"version: 2":             `invalid manifest: unsupported version 2`,
+		"version: 1\nextra: 1\n": `invalid manifest: line 2: field extra not found in type manifest.Manifest`,
+		"version: 1\nkeys: {k: {}}\n": `invalid manifest: key k: one of keyfile, key, ` +
+			`or product must be provided`,
+		"version: 1\nfiles: [{path: a.gcfg, key: k}]\n": `invalid manifest: file a.gcfg: ` +
+			`unknown key "k"`,
+		"version: 1\nkeys: {k: {product: connect}}\nfiles: [{path: a.txt, key: k}]\n": `invalid ` +
+			`manifest: file a.txt: format is missing and unknown configuration file format for a.txt`,
+		"version: 1\nkeys: {k: {product: connect}}\nfiles: [{path: a.gcfg, key: k, " +
+			"settings: [{name: S.N, env: A, file: b}]}]\n": `invalid manifest: file a.gcfg: ` +
+			`setting S.N: exactly one of env or file must be provided`,
+	} {
+		_, err := Load(strings.NewReader(text), s.dir)
+		c.Check(err, check.ErrorMatches, want, check.Commentf("%q", text))
+		c.Check(errors.Is(err, ErrInvalid), check.Equals, true)
+	}
+}
+
+func (s *ManifestSuite) TestPlanApply(c *check.C) {
+	s.write(c, "secrets/client-secret", "client-secret\n")
+	stale, _ := crypttest.NewKey("other").Encrypt("hunter2")
+	original := "[Postgres]\nPassword = " + stale + " ; the database password\n"
+	s.write(c, "rstudio-connect.gcfg", original)
+
+	m := s.load(c, example)
+	plan, err := s.planner.Plan(m)
+	c.Assert(err, check.IsNil)
+	var out strings.Builder
+	c.Assert(plan.WriteText(&out), check.IsNil)
+	c.Check(out.String(), check.Equals, strings.ReplaceAll(`  ~ DIR/rstudio-connect.gcfg
+      ~ Postgres.Password (cannot be decrypted with key `+s.key.Fingerprint()+`)
+      + OAuth2.ClientSecret (not set)
+  + DIR/database.conf (new file)
+      + password (not set)
+
+Plan: 2 to add, 1 to change, 0 unchanged.
+`, "DIR", s.dir))
+	// Plain text never appears in the plan.
+	c.Check(strings.Contains(out.String(), "hunter2"), check.Equals, false)
+
+	// Planning writes nothing.
+	data, _ := os.ReadFile(filepath.Join(s.dir, "rstudio-connect.gcfg"))
+	c.Check(string(data), check.Equals, original)
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	backups, err := plan.Apply(now)
+	c.Assert(err, check.IsNil)
+	backup := filepath.Join(s.dir, "rstudio-connect.gcfg.20261018T120000Z.bak")
+	c.Check(backups, check.DeepEquals, []string{backup})
+	data, _ = os.ReadFile(backup)
+	c.Check(string(data), check.Equals, original)
+	c.Check(s.get(c, "rstudio-connect.gcfg", "Postgres.Password"), check.Equals, "hunter2")
+	c.Check(s.get(c, "rstudio-connect.gcfg", "OAuth2.ClientSecret"), check.Equals, "client-secret")
+	c.Check(s.get(c, "database.conf", "password"), check.Equals, "token")
+	data, _ = os.ReadFile(filepath.Join(s.dir, "rstudio-connect.gcfg"))
+	c.Check(string(data), check.Matches, `(?s).*; the database password\n.*`)
+
+	// Permissions are kept, and new files are private.
+	info, _ := os.Stat(filepath.Join(s.dir, "rstudio-connect.gcfg"))
+	c.Check(info.Mode().Perm(), check.Equals, os.FileMode(0640))
+	info, _ = os.Stat(filepath.Join(s.dir, "database.conf"))
+	c.Check(info.Mode().Perm(), check.Equals, os.FileMode(0600))
+
+	// Applying again changes nothing.
+	plan, err = s.planner.Plan(m)
+	c.Assert(err, check.IsNil)
+	c.Check(plan.Empty(), check.Equals, true)
+	out.Reset()
+	c.Assert(plan.WriteText(&out), check.IsNil)
+	c.Check(out.String(), check.Matches, `(?s).*No changes. 3 settings are up to date.\n`)
+	backups, err = plan.Apply(now)
+	c.Assert(err, check.IsNil)
+	c.Check(backups, check.HasLen, 0)
+
+	// Changed values are detected.
+	s.env["TOKEN"] = "new-token"
+	plan, err = s.planner.Plan(m)
+	c.Assert(err, check.IsNil)
+	c.Check(plan.Files[1].Changes, check.DeepEquals, []Change{
+		{"password", Update, "value changed"},
+	})
+}
+
+func (s *ManifestSuite) TestReencrypt(c *check.C) {
+	if crypt.FIPSEnabled() {
+		c.Skip("the default mode already uses AES-256-GCM")
+	}
+	value, _ := s.key.Encrypt("hunter2")
+	s.write(c, "database.conf", "password="+value+"\n")
+	m := s.load(c, `version: 1
+keys: {k: {keyfile: secret.key, mode: fips}}
+files:
+  - path: database.conf
+    key: k
+    settings: [{name: password, env: DB_PASSWORD}]
+`)
+	plan, err := s.planner.Plan(m)
+	c.Assert(err, check.IsNil)
+	c.Check(plan.Files[0].Changes, check.DeepEquals, []Change{
+		{"password", Update, "re-encrypted"},
+	})
+}
+
+func (s *ManifestSuite) TestApplyRollback(c *check.C) {
+	original := "[Postgres]\nPassword = old\n"
+	path := s.write(c, "rstudio-connect.gcfg", original)
+	c.Assert(os.Mkdir(filepath.Join(s.dir, "sub"), 0700), check.IsNil)
+	plan, err := s.planner.Plan(s.load(c, `version: 1
+keys: {k: {keyfile: secret.key}}
+files:
+  - path: rstudio-connect.gcfg
+    key: k
+    settings: [{name: Postgres.Password, env: DB_PASSWORD}]
+  - path: sub/database.conf
+    key: k
+    settings: [{name: password, env: TOKEN}]
+`))
+	c.Assert(err, check.IsNil)
+	// The second file cannot be written.
+	c.Assert(os.Remove(filepath.Join(s.dir, "sub")), check.IsNil)
+
+	backups, err := plan.Apply(time.Now())
+	c.Check(err, check.NotNil)
+	c.Check(backups, check.HasLen, 0)
+	data, err := os.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Check(string(data), check.Equals, original)
+	matches, _ := filepath.Glob(filepath.Join(s.dir, "*.bak"))
+	c.Check(matches, check.HasLen, 0)
+}
+
+func (s *ManifestSuite) TestConflict(c *check.C) {
+	s.write(c, "secrets/client-secret", "client-secret")
+	path := s.write(c, "rstudio-connect.gcfg", "[Postgres]\n")
+	plan, err := s.planner.Plan(s.load(c, example))
+	c.Assert(err, check.IsNil)
+	s.write(c, "rstudio-connect.gcfg", "[Postgres]\nPassword = x\n")
+
+	// Nothing is written if any file changed.
+	_, err = plan.Apply(time.Now())
+	c.Check(err, check.ErrorMatches, path+`: file changed since it was planned`)
+	_, err = os.Stat(filepath.Join(s.dir, "database.conf"))
+	c.Check(os.IsNotExist(err), check.Equals, true)
+
+	// Missing sources are an error.
+	delete(s.env, "TOKEN")
+	_, err = s.planner.Plan(s.load(c, example))
+	c.Check(err, check.ErrorMatches, `.*database.conf: password: environment variable TOKEN is not set`)
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&ManifestSuite{})
+	check.TestingT(t)
+}
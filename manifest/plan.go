@@ -0,0 +1,320 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+)
+
+// ErrConflict reports a file that changed after it was planned.
+var ErrConflict = errors.New("file changed since it was planned")
+
+// Action is what applying a plan does to a setting or file.
+type Action string
+
+const (
+	// Create adds a setting or file.
+	Create Action = "create"
+	// Update changes a setting or file.
+	Update Action = "update"
+	// None leaves a setting or file unchanged.
+	None Action = "none"
+)
+
+// Symbol returns the prefix used for the action in plan output.
+func (a Action) Symbol() string {
+	switch a {
+	case Create:
+		return "+"
+	case Update:
+		return "~"
+	}
+	return " "
+}
+
+// Planner compares configuration files with a manifest.
+type Planner struct {
+	// Cipher returns the Cipher for a key in the manifest. It is used both
+	// to decrypt existing values and to encrypt new ones.
+	Cipher func(name string, spec KeySpec) (crypt.Cipher, error)
+	// LookupEnv is used for settings read from the environment. It
+	// defaults to os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+	// ReadFile is used for settings read from files. It defaults to
+	// os.ReadFile.
+	ReadFile func(string) ([]byte, error)
+}
+
+// Plan is the set of changes needed to make configuration files match a
+// manifest. It never contains plain text.
+type Plan struct {
+	Files []*FilePlan `json:"files"`
+}
+
+// FilePlan is the set of changes to a single file.
+type FilePlan struct {
+	Path    string          `json:"path"`
+	Format  conffile.Format `json:"format"`
+	Action  Action          `json:"action"`
+	Changes []Change        `json:"changes"`
+
+	// The file's contents when it was planned, or nil if it does not
+	// exist.
+	original []byte
+	doc      conffile.Document
+}
+
+// Change is a change to a single setting.
+type Change struct {
+	Name   string `json:"name"`
+	Action Action `json:"action"`
+	// Reason explains the action without revealing any plain text.
+	Reason string `json:"reason"`
+}
+
+// Plan reads the files in a manifest and works out which settings need to be
+// created or updated. Existing values are kept when they decrypt to the
+// desired plain text with the file's key.
+func (p *Planner) Plan(m *Manifest) (*Plan, error) {
+	ciphers := map[string]crypt.Cipher{}
+	plan := &Plan{}
+	for _, f := range m.Files {
+		c, ok := ciphers[f.Key]
+		if !ok {
+			var err error
+			c, err = p.Cipher(f.Key, m.Keys[f.Key])
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", f.Key, err)
+			}
+			ciphers[f.Key] = c
+		}
+		fp, err := p.planFile(f, c)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		plan.Files = append(plan.Files, fp)
+	}
+	return plan, nil
+}
+
+func (p *Planner) planFile(f FileSpec, c crypt.Cipher) (*FilePlan, error) {
+	fp := &FilePlan{Path: f.Path, Format: f.Format, Action: None, Changes: []Change{}}
+	data, err := os.ReadFile(f.Path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		fp.Action = Create
+	case err != nil:
+		return nil, err
+	default:
+		fp.original = data
+	}
+	fp.doc, err = conffile.Parse(f.Format, data)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range f.Settings {
+		plaintext, err := p.source(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		change := Change{Name: s.Name, Action: None, Reason: "up to date"}
+		var ciphertext string
+		previous, ok := fp.doc.Get(s.Name)
+		if !ok {
+			change.Action, change.Reason = Create, "not set"
+			ciphertext, err = c.Encrypt(plaintext)
+		} else {
+			ciphertext, err = crypt.EncryptStable(c, plaintext, previous)
+			if err == nil && ciphertext != previous {
+				change.Action, change.Reason = Update, reason(c, plaintext, previous)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		if change.Action != None {
+			if err := fp.doc.Set(s.Name, ciphertext); err != nil {
+				return nil, err
+			}
+			if fp.Action == None {
+				fp.Action = Update
+			}
+		}
+		fp.Changes = append(fp.Changes, change)
+	}
+	return fp, nil
+}
+
+// reason explains why a previous value must be replaced.
+func reason(c crypt.Cipher, plaintext, previous string) string {
+	current, err := c.Decrypt(previous)
+	switch {
+	case err != nil:
+		return "cannot be decrypted with key " + c.Fingerprint()
+	case subtle.ConstantTimeCompare([]byte(current), []byte(plaintext)) != 1:
+		return "value changed"
+	}
+	// The value is the same, but was not encrypted with the current mode.
+	return "re-encrypted"
+}
+
+// source reads the plain text of a setting.
+func (p *Planner) source(s Setting) (string, error) {
+	if s.Env != "" {
+		lookup := p.LookupEnv
+		if lookup == nil {
+			lookup = os.LookupEnv
+		}
+		value, ok := lookup(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		return value, nil
+	}
+	readFile := p.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	data, err := readFile(s.File)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+}
+
+// Counts returns the number of settings that would be created, updated, and
+// left unchanged.
+func (p *Plan) Counts() (create, update, none int) {
+	for _, f := range p.Files {
+		for _, c := range f.Changes {
+			switch c.Action {
+			case Create:
+				create++
+			case Update:
+				update++
+			default:
+				none++
+			}
+		}
+	}
+	return create, update, none
+}
+
+// Empty reports whether applying the plan would change nothing.
+func (p *Plan) Empty() bool {
+	create, update, _ := p.Counts()
+	return create+update == 0
+}
+
+// WriteText writes a summary of the plan in the style of Terraform.
+func (p *Plan) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, f := range p.Files {
+		switch f.Action {
+		case None:
+			fmt.Fprintf(&b, "  %s %s (no changes)\n", f.Action.Symbol(), f.Path)
+			continue
+		case Create:
+			fmt.Fprintf(&b, "  %s %s (new file)\n", f.Action.Symbol(), f.Path)
+		default:
+			fmt.Fprintf(&b, "  %s %s\n", f.Action.Symbol(), f.Path)
+		}
+		for _, c := range f.Changes {
+			if c.Action != None {
+				fmt.Fprintf(&b, "      %s %s (%s)\n", c.Action.Symbol(), c.Name, c.Reason)
+			}
+		}
+	}
+	create, update, none := p.Counts()
+	if create+update == 0 {
+		fmt.Fprintf(&b, "\nNo changes. %d settings are up to date.\n", none)
+	} else {
+		fmt.Fprintf(&b, "\nPlan: %d to add, %d to change, %d unchanged.\n", create, update, none)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Apply writes the planned changes. Each file that already exists is first
+// backed up to PATH.TIMESTAMP.bak, and then atomically replaced. Nothing is
+// written if any file has changed since it was planned, and if writing any
+// file fails, the files already written are restored and the backups removed,
+// so that the files are never left half-applied. Apply returns the paths of
+// the backups.
+func (p *Plan) Apply(now time.Time) ([]string, error) {
+	for _, f := range p.Files {
+		if f.Action == None {
+			continue
+		}
+		data, err := os.ReadFile(f.Path)
+		missing := errors.Is(err, fs.ErrNotExist)
+		if err != nil && !missing {
+			return nil, err
+		}
+		if missing != (f.original == nil) || !bytes.Equal(data, f.original) {
+			return nil, fmt.Errorf("%s: %w", f.Path, ErrConflict)
+		}
+	}
+	backups := []string{}
+	var written []*FilePlan
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, f := range p.Files {
+		if f.Action == None {
+			continue
+		}
+		if f.original != nil {
+			info, err := os.Stat(f.Path)
+			if err != nil {
+				return p.rollback(written, backups, err)
+			}
+			backup := f.Path + "." + stamp + ".bak"
+			if err := conffile.WriteFile(backup, f.original, info.Mode().Perm()); err != nil {
+				return p.rollback(written, backups, err)
+			}
+			backups = append(backups, backup)
+		}
+		// New files contain secrets, so are only readable by their owner.
+		if err := conffile.WriteFile(f.Path, f.doc.Bytes(), conffile.ExistingPerm(f.Path, 0600)); err != nil {
+			return p.rollback(written, backups, err)
+		}
+		written = append(written, f)
+	}
+	return backups, nil
+}
+
+// rollback restores the files that Apply has written to their original
+// contents after err, removing any that were created. The backups are removed
+// too, unless a file cannot be restored, in which case they are returned.
+func (p *Plan) rollback(written []*FilePlan, backups []string, err error) ([]string, error) {
+	errs := []error{err}
+	for _, f := range slices.Backward(written) {
+		if f.original == nil {
+			err = os.Remove(f.Path)
+		} else {
+			err = conffile.WriteFile(f.Path, f.original, conffile.ExistingPerm(f.Path, 0600))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", f.Path, err))
+		}
+	}
+	if len(errs) > 1 {
+		return backups, errors.Join(errs...)
+	}
+	for _, backup := range backups {
+		os.Remove(backup)
+	}
+	return nil, errs[0]
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package product describes the Posit products that use rskey keys: where
+// they keep their key by default, and which encryption modes each version can
+// decrypt.
+package product
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownProduct reports a product name that is not recognised.
+	ErrUnknownProduct = errors.New("unknown product")
+	// ErrUnsupported reports an encryption mode that a product (or a
+	// particular version of it) cannot decrypt.
+	ErrUnsupported = errors.New("unsupported by product")
+)
+
+// Version is a product version such as 2024.04.0. The zero Version means the
+// version is unknown.
+type Version struct {
+	Year, Month, Patch int
+}
+
+// ParseVersion parses a version of the form YEAR.MONTH[.PATCH], ignoring any
+// build or pre-release suffix after "+" or "-". An empty string parses as the
+// zero Version.
+func ParseVersion(s string) (Version, error) {
+	if s == "" {
+		return Version{}, nil
+	}
+	trimmed, _, _ := strings.Cut(s, "+")
+	trimmed, _, _ = strings.Cut(trimmed, "-")
+	parts := strings.Split(trimmed, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid product version %q", s)
+	}
+	nums := [3]int{}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid product version %q", s)
+		}
+		nums[i] = n
+	}
+	return Version{nums[0], nums[1], nums[2]}, nil
+}
+
+// IsZero reports whether the version is unknown.
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+// Before reports whether v is earlier than other.
+func (v Version) Before(other Version) bool {
+	if v.Year != other.Year {
+		return v.Year < other.Year
+	}
+	if v.Month != other.Month {
+		return v.Month < other.Month
+	}
+	return v.Patch < other.Patch
+}
+
+// String returns the version in the form used by Posit, e.g. 2024.04.0.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%02d.%d", v.Year, v.Month, v.Patch)
+}
+
+// modeSupport records the first version of a product that can decrypt
+// secrets in a given mode.
+type modeSupport struct {
+	mode  string
+	since Version
+}
+
+// Product describes a Posit product.
+type Product struct {
+	// Name is used to select the product, e.g. on the command line.
+	Name string
+	// Title is the product's full name.
+	Title string
+	// KeyPath is where the product keeps its key by default.
+	KeyPath string
+	// modes lists the supported modes, weakest first.
+	modes []modeSupport
+}
+
+var products = []*Product{
+	{
+		Name:    "connect",
+		Title:   "Posit Connect",
+		KeyPath: "/var/lib/rstudio-connect/db/secret.key",
+		modes: []modeSupport{
+			{"default", Version{}},
+			{"fips", Version{2022, 3, 0}},
+		},
+	},
+	{
+		Name:    "package-manager",
+		Title:   "Posit Package Manager",
+		KeyPath: "/var/lib/rstudio-pm/rstudio-pm.key",
+		modes: []modeSupport{
+			{"default", Version{}},
+			{"fips", Version{2024, 4, 0}},
+		},
+	},
+	{
+		Name:    "workbench",
+		Title:   "Posit Workbench",
+		KeyPath: "/etc/rstudio/secure-cookie-key",
+		modes: []modeSupport{
+			{"workbench", Version{}},
+		},
+	},
+}
+
+// Products returns every known product.
+func Products() []*Product {
+	return products
+}
+
+// Lookup returns the product with the given name.
+func Lookup(name string) (*Product, error) {
+	for _, p := range products {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownProduct, name)
+}
+
+// Names lists the names of every known product.
+func Names() []string {
+	names := []string{}
+	for _, p := range products {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Mode returns the strongest mode that version v of the product can decrypt.
+// If the version is unknown, it returns the mode that every version can
+// decrypt.
+func (p *Product) Mode(v Version) string {
+	mode := p.modes[0].mode
+	if v.IsZero() {
+		return mode
+	}
+	for _, m := range p.modes {
+		if !v.Before(m.since) {
+			mode = m.mode
+		}
+	}
+	return mode
+}
+
+// Choose checks that version v of the product can decrypt secrets encrypted
+// in the given mode, and returns that mode. If mode is empty, the strongest
+// supported mode is chosen as in Mode(). If the version is unknown, only the
+// mode itself is checked.
+//
+// When secretbox is false, the "default" mode is treated as "fips", because
+// keys in that mode encrypt with AES-256-GCM in FIPS mode or when the crypto
+// policy does not allow secretbox (see crypt.EncryptsSecretbox). An error is
+// returned if the version cannot decrypt the result.
+func (p *Product) Choose(mode string, v Version, secretbox bool) (string, error) {
+	if mode == "" {
+		mode = p.Mode(v)
+	}
+	effective := mode
+	if !secretbox && mode == "default" {
+		effective = "fips"
+	}
+	for _, m := range p.modes {
+		if m.mode != effective {
+			continue
+		}
+		if !v.IsZero() && v.Before(m.since) {
+			return "", fmt.Errorf("%w: %s %s cannot decrypt %q secrets; %s or later is required",
+				ErrUnsupported, p.Title, v, effective, m.since)
+		}
+		return mode, nil
+	}
+	return "", fmt.Errorf("%w: %s cannot decrypt %q secrets", ErrUnsupported, p.Title, effective)
+}
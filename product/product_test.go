@@ -0,0 +1,103 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package product
+
+import (
+	"errors"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+type ProductSuite struct{}
+
+func (s *ProductSuite) TestParseVersion(c *check.C) {
+	cases := []struct {
+		input string
+		want  Version
+	}{
+		{"", Version{}},
+		{"2024.04.0", Version{2024, 4, 0}},
+		{"2022.03", Version{2022, 3, 0}},
+		{"2023.12.1+402.pro1", Version{2023, 12, 1}},
+		{"2025.01.0-daily", Version{2025, 1, 0}},
+	}
+	for _, tc := range cases {
+		v, err := ParseVersion(tc.input)
+		c.Check(err, check.IsNil)
+		c.Check(v, check.Equals, tc.want, check.Commentf("%q", tc.input))
+	}
+	for _, bad := range []string{"2024", "2024.x.0", "1.2.3.4", "-1.0"} {
+		_, err := ParseVersion(bad)
+		c.Check(err, check.ErrorMatches, `invalid product version .*`)
+	}
+	c.Check(Version{2024, 4, 0}.String(), check.Equals, "2024.04.0")
+	c.Check(Version{2024, 4, 0}.Before(Version{2024, 4, 1}), check.Equals, true)
+	c.Check(Version{2024, 4, 0}.Before(Version{2023, 12, 9}), check.Equals, false)
+}
+
+func (s *ProductSuite) TestLookup(c *check.C) {
+	p, err := Lookup("package-manager")
+	c.Assert(err, check.IsNil)
+	c.Check(p.KeyPath, check.Equals, "/var/lib/rstudio-pm/rstudio-pm.key")
+	_, err = Lookup("shiny-server")
+	c.Check(errors.Is(err, ErrUnknownProduct), check.Equals, true)
+	c.Check(Names(), check.DeepEquals, []string{"connect", "package-manager", "workbench"})
+}
+
+func (s *ProductSuite) TestMode(c *check.C) {
+	connect, _ := Lookup("connect")
+	c.Check(connect.Mode(Version{}), check.Equals, "default")
+	c.Check(connect.Mode(Version{2021, 12, 0}), check.Equals, "default")
+	c.Check(connect.Mode(Version{2022, 3, 0}), check.Equals, "fips")
+	ppm, _ := Lookup("package-manager")
+	c.Check(ppm.Mode(Version{2023, 12, 0}), check.Equals, "default")
+	c.Check(ppm.Mode(Version{2024, 4, 2}), check.Equals, "fips")
+	wb, _ := Lookup("workbench")
+	c.Check(wb.Mode(Version{2024, 4, 2}), check.Equals, "workbench")
+}
+
+func (s *ProductSuite) TestChoose(c *check.C) {
+	connect, _ := Lookup("connect")
+	old := Version{2021, 9, 0}
+	cases := []struct {
+		mode      string
+		v         Version
+		secretbox bool
+		want      string
+		err       string
+	}{
+		{"", Version{}, true, "default", ""},
+		{"", Version{2024, 1, 0}, true, "fips", ""},
+		{"", old, true, "default", ""},
+		{"default", Version{2024, 1, 0}, true, "default", ""},
+		{"fips", Version{}, true, "fips", ""},
+		{"fips", old, true, "", `.*Posit Connect 2021.09.0 cannot decrypt "fips" secrets; 2022.03.0 or later is required`},
+		{"", old, false, "", `.*Posit Connect 2021.09.0 cannot decrypt "fips" secrets.*`},
+		{"default", Version{2022, 3, 0}, false, "default", ""},
+		{"workbench", Version{}, true, "", `.*Posit Connect cannot decrypt "workbench" secrets`},
+	}
+	for _, tc := range cases {
+		comment := check.Commentf("%+v", tc)
+		got, err := connect.Choose(tc.mode, tc.v, tc.secretbox)
+		if tc.err != "" {
+			c.Check(err, check.ErrorMatches, tc.err, comment)
+			c.Check(errors.Is(err, ErrUnsupported), check.Equals, true, comment)
+			continue
+		}
+		c.Check(err, check.IsNil, comment)
+		c.Check(got, check.Equals, tc.want, comment)
+	}
+	wb, _ := Lookup("workbench")
+	got, err := wb.Choose("", Version{}, false)
+	c.Check(err, check.IsNil)
+	c.Check(got, check.Equals, "workbench")
+	_, err = wb.Choose("fips", Version{}, true)
+	c.Check(errors.Is(err, ErrUnsupported), check.Equals, true)
+}
+
+func Test(t *testing.T) {
+	check.Suite(&ProductSuite{})
+	check.TestingT(t)
+}
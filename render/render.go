@@ -0,0 +1,126 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package render produces configuration files from text/template templates
+// that can encrypt values, such as secrets read from the environment, as they
+// are rendered.
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+var (
+	// ErrNoKey reports a template function that needs a key that was not
+	// provided.
+	ErrNoKey = errors.New("no key was provided")
+	// ErrNoFIPS reports a key that cannot encrypt with encryptFIPS.
+	ErrNoFIPS = errors.New("key does not support FIPS encryption")
+)
+
+// Renderer renders templates with the following functions:
+//
+//	encrypt PLAINTEXT           encrypt with Cipher
+//	encryptFIPS PLAINTEXT       encrypt with Cipher, using AES-256-GCM
+//	workbenchEncrypt PLAINTEXT  encrypt with Workbench
+//	fingerprint                 the fingerprint of Cipher
+//	env NAME                    an environment variable, which must be set
+//	file PATH                   the contents of a file, without a trailing newline
+//
+// Plain text only ever appears in the rendered output: it is never included in
+// errors, and nothing is written if rendering fails.
+type Renderer struct {
+	// Cipher is used by encrypt, encryptFIPS, and fingerprint. For
+	// encryptFIPS, it must be a *crypt.Key or a Cipher with a
+	// FIPSCipher() method.
+	Cipher crypt.Cipher
+	// Workbench is used by workbenchEncrypt.
+	Workbench crypt.Cipher
+	// LookupEnv is used by env. It defaults to os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+	// ReadFile is used by file. It defaults to os.ReadFile.
+	ReadFile func(string) ([]byte, error)
+}
+
+// Funcs returns the template functions described above.
+func (r *Renderer) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"encrypt": func(s string) (string, error) {
+			if r.Cipher == nil {
+				return "", fmt.Errorf("encrypt: %w", ErrNoKey)
+			}
+			return r.Cipher.Encrypt(s)
+		},
+		"encryptFIPS": func(s string) (string, error) {
+			if r.Cipher == nil {
+				return "", fmt.Errorf("encryptFIPS: %w", ErrNoKey)
+			}
+			f, ok := r.Cipher.(interface{ FIPSCipher() crypt.Cipher })
+			if !ok {
+				return "", fmt.Errorf("encryptFIPS: %w", ErrNoFIPS)
+			}
+			return f.FIPSCipher().Encrypt(s)
+		},
+		"workbenchEncrypt": func(s string) (string, error) {
+			if r.Workbench == nil {
+				return "", fmt.Errorf("workbenchEncrypt: %w", ErrNoKey)
+			}
+			return r.Workbench.Encrypt(s)
+		},
+		"fingerprint": func() (string, error) {
+			if r.Cipher == nil {
+				return "", fmt.Errorf("fingerprint: %w", ErrNoKey)
+			}
+			return r.Cipher.Fingerprint(), nil
+		},
+		"env": func(name string) (string, error) {
+			lookup := r.LookupEnv
+			if lookup == nil {
+				lookup = os.LookupEnv
+			}
+			value, ok := lookup(name)
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			return value, nil
+		},
+		"file": func(path string) (string, error) {
+			read := r.ReadFile
+			if read == nil {
+				read = os.ReadFile
+			}
+			data, err := read(path)
+			if err != nil {
+				return "", err
+			}
+			s := strings.TrimSuffix(string(data), "\n")
+			return strings.TrimSuffix(s, "\r"), nil
+		},
+	}
+}
+
+// Render parses and executes a template, writing the result to w only if it
+// succeeds. Missing map keys are an error.
+func (r *Renderer) Render(w io.Writer, name, text string) error {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(r.Funcs()).
+		Parse(text)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/crypttest"
+)
+
+type RenderSuite struct {
+	key       *crypt.Key
+	workbench crypt.Cipher
+	renderer  *Renderer
+}
+
+func (s *RenderSuite) SetUpTest(c *check.C) {
+	s.key = crypttest.NewKey("render")
+	s.workbench = crypttest.NewWorkbenchKey("render")
+	env := map[string]string{"DB_PASSWORD": "hunter2", "EMPTY": ""}
+	s.renderer = &Renderer{
+		Cipher:    s.key,
+		Workbench: s.workbench,
+		LookupEnv: func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		},
+	}
+}
+
+var valuePattern = regexp.MustCompile(`(?m)^(\w+) = (.*)$`)
+
+func (s *RenderSuite) render(c *check.C, text string) map[string]string {
+	var buf bytes.Buffer
+	c.Assert(s.renderer.Render(&buf, "test", text), check.IsNil)
+	out := map[string]string{}
+	for _, m := range valuePattern.FindAllStringSubmatch(buf.String(), -1) {
+		out[m[1]] = m[2]
+	}
+	return out
+}
+
+func (s *RenderSuite) TestRender(c *check.C) {
+	path := c.MkDir() + "/secret"
+	c.Assert(os.WriteFile(path, []byte("from a file\n"), 0600), check.IsNil)
+	out := s.render(c, `[Database]
+Password = {{ env "DB_PASSWORD" | encrypt }}
+FIPS = {{ env "DB_PASSWORD" | encryptFIPS }}
+Workbench = {{ env "DB_PASSWORD" | workbenchEncrypt }}
+File = {{ file "`+path+`" | encrypt }}
+Empty = {{ env "EMPTY" }}
+Fingerprint = {{ fingerprint }}
+`)
+	for _, name := range []string{"Password", "FIPS", "File"} {
+		c.Check(out[name], check.Not(check.Matches), `.*hunter2.*`)
+	}
+	text, err := s.key.Decrypt(out["Password"])
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+	buf, _ := base64.StdEncoding.DecodeString(out["FIPS"])
+	c.Check(buf[0], check.Equals, byte(2))
+	text, err = s.key.Decrypt(out["FIPS"])
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+	text, err = s.workbench.Decrypt(out["Workbench"])
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "hunter2")
+	text, err = s.key.Decrypt(out["File"])
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "from a file")
+	c.Check(out["Empty"], check.Equals, "")
+	c.Check(out["Fingerprint"], check.Equals, s.key.Fingerprint())
+}
+
+func (s *RenderSuite) TestErrors(c *check.C) {
+	cases := []struct {
+		renderer *Renderer
+		text     string
+		err      string
+	}{
+		{s.renderer, `{{ env "MISSING" }}`, `.*environment variable MISSING is not set`},
+		{s.renderer, `{{ file "/does/not/exist" }}`, `.*no such file or directory`},
+		{s.renderer, `{{ encrypt }`, `.*unexpected "}".*`},
+		{&Renderer{}, `{{ "x" | encrypt }}`, `.*encrypt: no key was provided`},
+		{&Renderer{}, `{{ "x" | workbenchEncrypt }}`, `.*workbenchEncrypt: no key was provided`},
+		{&Renderer{Cipher: s.workbench}, `{{ "x" | encryptFIPS }}`,
+			`.*encryptFIPS: key does not support FIPS encryption`},
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		err := tc.renderer.Render(&buf, "test", "password = hunter2\n"+tc.text)
+		c.Check(err, check.ErrorMatches, tc.err, check.Commentf("%s", tc.text))
+		// Nothing is written on failure, and errors never contain plain
+		// text.
+		c.Check(buf.Len(), check.Equals, 0)
+		c.Check(strings.Contains(err.Error(), "hunter2"), check.Equals, false)
+	}
+	err := (&Renderer{}).Render(&bytes.Buffer{}, "test", `{{ "x" | encrypt }}`)
+	c.Check(errors.Is(err, ErrNoKey), check.Equals, true)
+}
+
+func Test(t *testing.T) {
+	check.Suite(&RenderSuite{})
+	check.TestingT(t)
+}
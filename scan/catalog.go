@@ -0,0 +1,66 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package scan
+
+import (
+	"path"
+	"strings"
+
+	"github.com/rstudio/rskey/conffile"
+)
+
+// Setting describes a product setting that holds a secret and accepts an
+// encrypted value.
+type Setting struct {
+	// Product is the name of the product, as in product.Lookup().
+	Product string
+	// Files are glob patterns matched against the base name of
+	// configuration files.
+	Files []string
+	// Format is the syntax of those files.
+	Format conffile.Format
+	// Name is a glob pattern matched case-insensitively against setting
+	// names, in the form of conffile.Entry.Name. For gcfg files, "*"
+	// matches any subsection.
+	Name string
+}
+
+var (
+	connectFiles        = []string{"rstudio-connect.gcfg"}
+	packageManagerFiles = []string{"rstudio-pm.gcfg"}
+	workbenchFiles      = []string{"database.conf"}
+)
+
+var catalog = []Setting{
+	{"connect", connectFiles, conffile.GCFG, "Postgres.Password"},
+	{"connect", connectFiles, conffile.GCFG, "Postgres.InstrumentationPassword"},
+	{"connect", connectFiles, conffile.GCFG, "LDAP.*.BindPassword"},
+	{"connect", connectFiles, conffile.GCFG, "OAuth2.ClientSecret"},
+	{"connect", connectFiles, conffile.GCFG, "SMTP.Password"},
+	{"package-manager", packageManagerFiles, conffile.GCFG, "Postgres.Password"},
+	{"package-manager", packageManagerFiles, conffile.GCFG, "Postgres.UsageDataPassword"},
+	{"workbench", workbenchFiles, conffile.Workbench, "password"},
+}
+
+// Catalog returns the built-in catalog of sensitive settings.
+func Catalog() []Setting {
+	return catalog
+}
+
+// MatchFile reports whether a file with the given path holds the setting.
+func (s Setting) MatchFile(name string) bool {
+	base := path.Base(strings.ReplaceAll(name, `\`, "/"))
+	for _, pattern := range s.Files {
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchName reports whether a setting name refers to the setting.
+func (s Setting) MatchName(name string) bool {
+	ok, _ := path.Match(strings.ToLower(s.Name), strings.ToLower(name))
+	return ok
+}
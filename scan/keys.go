@@ -0,0 +1,141 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package scan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/workbench"
+)
+
+var (
+	// RuleKeyMaterial reports an encryption key found in a file.
+	RuleKeyMaterial = Rule{"key-material", "File contains an encryption key", LevelError}
+	// RuleDecryptable reports cipher text that a known key can decrypt.
+	RuleDecryptable = Rule{"decryptable-secret", "Cipher text can be decrypted with the known key", LevelNote}
+)
+
+// token matches text that might be a key or cipher text. The shortest cipher
+// text any supported mode produces is 40 characters of base64.
+var token = regexp.MustCompile(`[A-Za-z0-9+/]{40,}={0,2}`)
+
+// minKeyTokenLength is the length of the shortest encoding of a Key, which is
+// padded base64.
+const minKeyTokenLength = 684
+
+// workbenchKeyFiles are the names of files that hold Workbench keys, which
+// have no distinctive format of their own. Files whose names start with them,
+// such as backups like secure-cookie-key.bak, are included.
+var workbenchKeyFiles = []string{"secure-cookie-key"}
+
+// uuidFile matches files that hold nothing but a UUID, which is how Workbench
+// keys are usually generated, whatever the file is called.
+var uuidFile = regexp.MustCompile(
+	`^\s*[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\s*$`)
+
+// KeyScanner finds encryption keys in files, and optionally the cipher text
+// that a known key can decrypt.
+type KeyScanner struct {
+	// Key, if set, is used to find cipher text that it can decrypt.
+	Key crypt.Cipher
+}
+
+// ScanFile checks the contents of the file at path.
+func (s *KeyScanner) ScanFile(name string, data []byte) []Finding {
+	findings := []Finding{}
+	if isWorkbenchKeyFile(name, data) {
+		if key, err := workbench.NewKeyFromBytes(data); err == nil {
+			findings = append(findings, s.keyFinding(name, 1, "Workbench", key.Fingerprint()))
+		} else if f, ok := s.policyFinding(name, 1, "Workbench", err); ok {
+			findings = append(findings, f)
+		}
+	}
+	for _, loc := range token.FindAllIndex(data, -1) {
+		t := data[loc[0]:loc[1]]
+		line := bytes.Count(data[:loc[0]], []byte("\n")) + 1
+		if len(t) >= minKeyTokenLength {
+			key, err := crypt.NewKeyFromBytes(t)
+			if err == nil {
+				findings = append(findings, s.keyFinding(name, line, "rskey", key.Fingerprint()))
+				continue
+			}
+			if f, ok := s.policyFinding(name, line, "rskey", err); ok {
+				findings = append(findings, f)
+				continue
+			}
+		}
+		if s.Key == nil {
+			continue
+		}
+		if _, err := s.Key.Decrypt(string(t)); err == nil {
+			findings = append(findings, Finding{
+				Rule:        RuleDecryptable.ID,
+				Path:        name,
+				Line:        line,
+				Fingerprint: s.Key.Fingerprint(),
+				Message:     fmt.Sprintf("cipher text can be decrypted with key %s", s.Key.Fingerprint()),
+			})
+		}
+	}
+	return findings
+}
+
+func (s *KeyScanner) keyFinding(name string, line int, kind, fingerprint string) Finding {
+	message := fmt.Sprintf("%s key %s", kind, fingerprint)
+	if s.Key != nil && s.Key.Fingerprint() == fingerprint {
+		message += ", which is the known key"
+	}
+	return Finding{
+		Rule:        RuleKeyMaterial.ID,
+		Path:        name,
+		Line:        line,
+		Fingerprint: fingerprint,
+		Message:     message,
+	}
+}
+
+// policyFinding reports a key that the crypto policy refused to load, which
+// is most likely one that has already been revoked.
+func (s *KeyScanner) policyFinding(name string, line int, kind string, err error) (Finding, bool) {
+	var perr *crypt.PolicyError
+	if !errors.As(err, &perr) {
+		return Finding{}, false
+	}
+	return Finding{
+		Rule:    RuleKeyMaterial.ID,
+		Path:    name,
+		Line:    line,
+		Message: fmt.Sprintf("%s key refused by crypto policy: %s", kind, perr.Reason),
+	}, true
+}
+
+// isWorkbenchKeyFile reports whether a file looks like a Workbench key, either
+// by its name or by holding only a UUID.
+func isWorkbenchKeyFile(name string, data []byte) bool {
+	if uuidFile.Match(data) {
+		return true
+	}
+	base := strings.ToLower(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	for _, f := range workbenchKeyFiles {
+		if strings.HasPrefix(base, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBinary reports whether data looks like the contents of a binary file,
+// which is not worth scanning.
+func IsBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package scan
+
+import (
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypttest"
+)
+
+func (s *ScanSuite) TestKeyScanner(c *check.C) {
+	key := crypttest.NewKey("keys")
+	other := crypttest.NewKey("other")
+	data := "# Accidentally committed\nKEY=" + key.HexString() + "\n" +
+		"not a key: " + strings.Repeat("ab", 600) + "\n" +
+		"password: " + crypttest.Encrypt(c, key, "hunter2") + "\n" +
+		"token: ENC[" + crypttest.Encrypt(c, other, "token") + "]\n"
+
+	scanner := &KeyScanner{}
+	findings := scanner.ScanFile("config/app.env", []byte(data))
+	c.Check(findings, check.DeepEquals, []Finding{{
+		Rule:        RuleKeyMaterial.ID,
+		Path:        "config/app.env",
+		Line:        2,
+		Fingerprint: key.Fingerprint(),
+		Message:     "rskey key " + key.Fingerprint(),
+	}})
+
+	// With a known key, the cipher text it can decrypt is listed too.
+	scanner.Key = key
+	findings = scanner.ScanFile("config/app.env", []byte(data))
+	c.Assert(findings, check.HasLen, 2)
+	c.Check(findings[0].Message, check.Equals, "rskey key "+key.Fingerprint()+", which is the known key")
+	c.Check(findings[1], check.DeepEquals, Finding{
+		Rule:        RuleDecryptable.ID,
+		Path:        "config/app.env",
+		Line:        4,
+		Fingerprint: key.Fingerprint(),
+		Message:     "cipher text can be decrypted with key " + key.Fingerprint(),
+	})
+	c.Check(findings[1].Level(), check.Equals, LevelNote)
+
+	// Workbench keys are recognised by the name of their file, or by being a
+	// UUID.
+	wbKey := crypttest.NewWorkbenchKey("keys")
+	wbData := crypttest.WorkbenchKeyData("keys")
+	for _, name := range []string{
+		"etc/rstudio/secure-cookie-key", "backup/secure-cookie-key.bak", "notes/key.txt",
+	} {
+		findings = (&KeyScanner{}).ScanFile(name, wbData)
+		c.Assert(findings, check.HasLen, 1, check.Commentf("%s", name))
+		c.Check(findings[0].Fingerprint, check.Equals, wbKey.Fingerprint())
+		c.Check(findings[0].Message, check.Equals, "Workbench key "+wbKey.Fingerprint())
+	}
+	// Only Workbench cipher text that decrypts with valid padding is listed.
+	data = "password=" + crypttest.Encrypt(c, wbKey, "secret") + "\ncorrupt=" +
+		crypttest.CorruptWorkbenchValue(wbKey) + "\n"
+	findings = (&KeyScanner{Key: wbKey}).ScanFile("etc/rstudio/database.conf", []byte(data))
+	c.Assert(findings, check.HasLen, 1)
+	c.Check(findings[0].Rule, check.Equals, RuleDecryptable.ID)
+	c.Check(findings[0].Line, check.Equals, 1)
+
+	passphrase := []byte("a long Workbench key that is not a UUID\n")
+	c.Check((&KeyScanner{}).ScanFile("etc/rstudio/Secure-Cookie-Key.orig", passphrase), check.HasLen, 1)
+	c.Check((&KeyScanner{}).ScanFile("etc/rstudio/notes.txt", passphrase), check.HasLen, 0)
+	c.Check((&KeyScanner{}).ScanFile("ids.txt", append(wbData, wbData...)), check.HasLen, 0)
+}
+
+func (s *ScanSuite) TestIsBinary(c *check.C) {
+	c.Check(IsBinary([]byte("text\n")), check.Equals, false)
+	c.Check(IsBinary([]byte("\x89PNG\r\n\x1a\n\x00\x00")), check.Equals, true)
+}
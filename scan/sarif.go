@@ -0,0 +1,96 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package scan
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+// The subset of SARIF 2.1.0 used by WriteSARIF.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string       `json:"id"`
+		ShortDescription     sarifMessage `json:"shortDescription"`
+		DefaultConfiguration struct {
+			Level Level `json:"level"`
+		} `json:"defaultConfiguration"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     Level           `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifLocation struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region *sarifRegion `json:"region,omitempty"`
+		} `json:"physicalLocation"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+// WriteSARIF writes findings as a SARIF 2.1.0 log, for code scanning tools.
+// The version is that of rskey.
+func WriteSARIF(w io.Writer, findings []Finding, version string) error {
+	driver := sarifDriver{
+		Name:           "rskey",
+		Version:        version,
+		InformationURI: "https://github.com/rstudio/rskey",
+		Rules:          []sarifRule{},
+	}
+	for _, r := range Rules() {
+		rule := sarifRule{ID: r.ID, ShortDescription: sarifMessage{r.Description}}
+		rule.DefaultConfiguration.Level = r.Level
+		driver.Rules = append(driver.Rules, rule)
+	}
+	results := []sarifResult{}
+	for _, f := range findings {
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(f.Path)
+		if f.Line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{f.Line}
+		}
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     f.Level(),
+			Message:   sarifMessage{f.Message},
+			Locations: []sarifLocation{loc},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{driver}, Results: results}},
+	})
+}
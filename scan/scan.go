@@ -0,0 +1,160 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package scan finds secrets that have leaked into configuration files and
+// source trees, such as plain text passwords in settings that should be
+// encrypted.
+package scan
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/rstudio/rskey/conffile"
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/workbench"
+)
+
+// Level is how serious a Finding is, using the names from SARIF.
+type Level string
+
+const (
+	// LevelError findings are problems that should be fixed.
+	LevelError Level = "error"
+	// LevelNote findings are informational.
+	LevelNote Level = "note"
+)
+
+// Rule identifies a kind of Finding.
+type Rule struct {
+	ID          string
+	Description string
+	Level       Level
+}
+
+var (
+	// RulePlaintext reports a sensitive setting with a plain text value.
+	RulePlaintext = Rule{"plaintext-secret", "Sensitive setting is not encrypted", LevelError}
+	// RuleUndecryptable reports a sensitive setting with an encrypted value
+	// that the product's key cannot decrypt.
+	RuleUndecryptable = Rule{"undecryptable-secret", "Encrypted setting cannot be decrypted with the key", LevelError}
+)
+
+// Rules lists every rule, in a stable order.
+func Rules() []Rule {
+	return []Rule{RulePlaintext, RuleUndecryptable, RuleKeyMaterial, RuleDecryptable}
+}
+
+// LookupRule returns the rule with the given ID.
+func LookupRule(id string) (Rule, bool) {
+	for _, r := range Rules() {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Finding is a single result of a scan.
+type Finding struct {
+	// Rule is the ID of the rule that was broken.
+	Rule    string `json:"rule"`
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Product string `json:"product,omitempty"`
+	Setting string `json:"setting,omitempty"`
+	// Fingerprint identifies the key a finding is about, if any.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Message     string `json:"message"`
+}
+
+// Level returns the level of the finding's rule.
+func (f Finding) Level() Level {
+	if r, ok := LookupRule(f.Rule); ok {
+		return r.Level
+	}
+	return LevelError
+}
+
+// Scanner checks configuration files for sensitive settings that are not
+// properly encrypted.
+type Scanner struct {
+	// Settings is the catalog of sensitive settings.
+	Settings []Setting
+	// Key returns the key to check encrypted values for a product with, or
+	// nil to only check that they look encrypted. Key itself may be nil.
+	Key func(product string) crypt.Cipher
+}
+
+// Matches reports whether the file at path holds any sensitive settings.
+func (s *Scanner) Matches(path string) bool {
+	return len(s.settings(path)) > 0
+}
+
+func (s *Scanner) settings(path string) []Setting {
+	var out []Setting
+	for _, setting := range s.Settings {
+		if setting.MatchFile(path) {
+			out = append(out, setting)
+		}
+	}
+	return out
+}
+
+// ScanFile checks the contents of the file at path. Files that hold no
+// sensitive settings are ignored.
+func (s *Scanner) ScanFile(path string, data []byte) ([]Finding, error) {
+	findings := []Finding{}
+	docs := map[conffile.Format]conffile.Document{}
+	for _, setting := range s.settings(path) {
+		doc, ok := docs[setting.Format]
+		if !ok {
+			var err error
+			doc, err = conffile.Parse(setting.Format, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			docs[setting.Format] = doc
+		}
+		var key crypt.Cipher
+		if s.Key != nil {
+			key = s.Key(setting.Product)
+		}
+		for _, e := range doc.Entries() {
+			if e.Value == "" || !setting.MatchName(e.Name) {
+				continue
+			}
+			f := Finding{Path: path, Line: e.Line, Product: setting.Product, Setting: e.Name}
+			switch {
+			case !looksEncrypted(setting.Product, e.Value):
+				f.Rule = RulePlaintext.ID
+				f.Message = fmt.Sprintf("%s has a plain text value; encrypt it with rskey", e.Name)
+			case key != nil && !decrypts(key, e.Value):
+				f.Rule = RuleUndecryptable.ID
+				f.Message = fmt.Sprintf("%s is encrypted, but not with key %s", e.Name, key.Fingerprint())
+			default:
+				continue
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+// minPayloadLength is the length of the shortest cipher text produced by any
+// mode other than Workbench's, once decoded.
+const minPayloadLength = 29
+
+// looksEncrypted reports whether s could be cipher text for the product.
+func looksEncrypted(product, s string) bool {
+	if product == "workbench" {
+		return workbench.IsEncrypted(s)
+	}
+	buf, err := base64.StdEncoding.DecodeString(s)
+	return err == nil && len(buf) >= minPayloadLength
+}
+
+func decrypts(c crypt.Cipher, s string) bool {
+	_, err := c.Decrypt(s)
+	return err == nil
+}
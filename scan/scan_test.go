@@ -0,0 +1,124 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/crypttest"
+)
+
+type ScanSuite struct{}
+
+func (s *ScanSuite) TestCatalog(c *check.C) {
+	setting := Setting{"connect", []string{"rstudio-connect.gcfg"}, "gcfg", "LDAP.*.BindPassword"}
+	c.Check(setting.MatchFile("/etc/rstudio-connect/rstudio-connect.gcfg"), check.Equals, true)
+	c.Check(setting.MatchFile(`C:\config\rstudio-connect.gcfg`), check.Equals, true)
+	c.Check(setting.MatchFile("rstudio-pm.gcfg"), check.Equals, false)
+	c.Check(setting.MatchName("ldap.My Server.bindpassword"), check.Equals, true)
+	c.Check(setting.MatchName("LDAP.BindPassword"), check.Equals, false)
+}
+
+func (s *ScanSuite) TestScanFile(c *check.C) {
+	key := crypttest.NewKey("scan")
+	other := crypttest.NewKey("other")
+	wbKey := crypttest.NewWorkbenchKey("scan")
+	scanner := &Scanner{
+		Settings: Catalog(),
+		Key: func(product string) crypt.Cipher {
+			if product == "connect" {
+				return key
+			}
+			return nil
+		},
+	}
+	c.Check(scanner.Matches("config/rstudio-connect.gcfg"), check.Equals, true)
+	c.Check(scanner.Matches("README.md"), check.Equals, false)
+
+	gcfg := "[Postgres]\nPassword = hunter2\nInstrumentationPassword = " + crypttest.Encrypt(c, other, "x") +
+		"\n\n[LDAP \"corp\"]\nBindPassword = " + crypttest.Encrypt(c, key, "y") + "\n\n[OAuth2]\nClientSecret =\n"
+	findings, err := scanner.ScanFile("rstudio-connect.gcfg", []byte(gcfg))
+	c.Assert(err, check.IsNil)
+	c.Check(findings, check.DeepEquals, []Finding{
+		{Rule: RulePlaintext.ID, Path: "rstudio-connect.gcfg", Line: 2, Product: "connect",
+			Setting: "Postgres.Password",
+			Message: "Postgres.Password has a plain text value; encrypt it with rskey"},
+		{Rule: RuleUndecryptable.ID, Path: "rstudio-connect.gcfg", Line: 3, Product: "connect",
+			Setting: "Postgres.InstrumentationPassword",
+			Message: "Postgres.InstrumentationPassword is encrypted, but not with key " + key.Fingerprint()},
+	})
+
+	// Without a key, only plain text is reported.
+	findings, err = scanner.ScanFile("rstudio-pm.gcfg", []byte(gcfg))
+	c.Assert(err, check.IsNil)
+	c.Check(findings, check.HasLen, 1)
+	c.Check(findings[0].Product, check.Equals, "package-manager")
+
+	conf := "provider=postgresql\npassword=" + crypttest.Encrypt(c, wbKey, "secret") + "\n"
+	findings, err = scanner.ScanFile("etc/database.conf", []byte(conf))
+	c.Assert(err, check.IsNil)
+	c.Check(findings, check.HasLen, 0)
+	findings, err = scanner.ScanFile("etc/database.conf", []byte("password="+crypttest.Encrypt(c, key, "secret")+"\n"))
+	c.Assert(err, check.IsNil)
+	c.Check(findings, check.HasLen, 1)
+	c.Check(findings[0].Rule, check.Equals, RulePlaintext.ID)
+
+	// Corrupt values are reported as undecryptable.
+	scanner.Key = func(product string) crypt.Cipher { return wbKey }
+	findings, err = scanner.ScanFile("etc/database.conf",
+		[]byte("password="+crypttest.CorruptWorkbenchValue(wbKey)+"\n"))
+	c.Assert(err, check.IsNil)
+	c.Assert(findings, check.HasLen, 1)
+	c.Check(findings[0].Rule, check.Equals, RuleUndecryptable.ID)
+
+	_, err = scanner.ScanFile("rstudio-connect.gcfg", []byte("Password = x\n"))
+	c.Check(err, check.ErrorMatches, `rstudio-connect.gcfg: .*`)
+}
+
+func (s *ScanSuite) TestWriteSARIF(c *check.C) {
+	var buf bytes.Buffer
+	err := WriteSARIF(&buf, []Finding{
+		{Rule: RulePlaintext.ID, Path: `etc\rstudio-connect.gcfg`, Line: 2, Message: "message"},
+	}, "1.0.0")
+	c.Assert(err, check.IsNil)
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine int }
+					}
+				}
+			}
+		}
+	}
+	c.Assert(json.Unmarshal(buf.Bytes(), &log), check.IsNil)
+	c.Check(log.Version, check.Equals, "2.1.0")
+	c.Assert(log.Runs, check.HasLen, 1)
+	c.Check(log.Runs[0].Tool.Driver.Rules, check.HasLen, len(Rules()))
+	c.Assert(log.Runs[0].Results, check.HasLen, 1)
+	result := log.Runs[0].Results[0]
+	c.Check(result.RuleID, check.Equals, RulePlaintext.ID)
+	c.Check(result.Level, check.Equals, "error")
+	c.Check(result.Locations[0].PhysicalLocation.Region.StartLine, check.Equals, 2)
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&ScanSuite{})
+	check.TestingT(t)
+}
@@ -0,0 +1,420 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+// Package selftest runs known-answer and round-trip tests against the
+// encryption formats used by Posit's products, using an embedded corpus of
+// fixed keys, plain texts, and cipher texts.
+package selftest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/rstudio/rskey/crypt"
+	"github.com/rstudio/rskey/workbench"
+)
+
+//go:embed corpus.json
+var corpus []byte
+
+// CorpusVersion is the version of the embedded corpus.
+const CorpusVersion = 1
+
+// Corpus is a versioned set of known-answer test vectors.
+type Corpus struct {
+	Version int               `json:"version"`
+	Keys    map[string]KeyDef `json:"keys"`
+	Vectors []Vector          `json:"vectors"`
+}
+
+// KeyDef is a fixed key in a Corpus.
+type KeyDef struct {
+	Mode        string `json:"mode"`
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Vector is a known plain text and cipher text pair.
+type Vector struct {
+	Name       string `json:"name"`
+	Key        string `json:"key"`
+	Algorithm  string `json:"algorithm"`
+	Plaintext  string `json:"plaintext"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	// Check is the kind of check, such as "decrypt" or "roundtrip".
+	Check string
+	// Name identifies what was checked.
+	Name string
+	// Note optionally explains a passing result.
+	Note string
+	// Err is nil if the check passed.
+	Err error
+}
+
+// Report collects the results of a self-test run.
+type Report struct {
+	CorpusVersion int
+	// CorpusDigest is the hex-encoded SHA-256 digest of the corpus.
+	CorpusDigest string
+	FIPS         crypt.FIPSStatus
+	Results      []Result
+}
+
+// Failures returns the number of failed checks.
+func (r *Report) Failures() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// SignOff identifies who signs off a report, and the key they sign it with.
+type SignOff struct {
+	// Name is recorded in a Signed-off-by line, if not empty.
+	Name string
+	// Key signs the report, if not nil.
+	Key ed25519.PrivateKey
+}
+
+const signaturePrefix = "Signature: ed25519 "
+
+// Write writes a human-readable version of the report, starting with the given
+// header and ending in a digest of everything written.
+func (r *Report) Write(w io.Writer, header string) error {
+	return r.WriteSigned(w, header, SignOff{})
+}
+
+// WriteSigned is like Write, but also records the name in the sign-off and,
+// given a key, ends the report with an Ed25519 signature over everything
+// before it. Signed reports can be checked with VerifyReport.
+func (r *Report) WriteSigned(w io.Writer, header string, s SignOff) error {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	fmt.Fprintf(&buf, "Corpus:               version %d, sha256 %s\n\n",
+		r.CorpusVersion, r.CorpusDigest)
+	for _, res := range r.Results {
+		status := "PASS"
+		detail := res.Note
+		if res.Err != nil {
+			status = "FAIL"
+			detail = res.Err.Error()
+		}
+		line := fmt.Sprintf("%s  %-11s %s", status, res.Check, res.Name)
+		if detail != "" {
+			line += " (" + detail + ")"
+		}
+		fmt.Fprintln(&buf, line)
+	}
+	result := "PASS"
+	if r.Failures() > 0 {
+		result = "FAIL"
+	}
+	fmt.Fprintf(&buf, "\nResult: %s (%d checks, %d failures)\n", result,
+		len(r.Results), r.Failures())
+	if s.Name != "" {
+		fmt.Fprintf(&buf, "Signed-off-by: %s\n", s.Name)
+	}
+	fmt.Fprintf(&buf, "Report sha256: %x\n", sha256.Sum256(buf.Bytes()))
+	if s.Key != nil {
+		pub := s.Key.Public().(ed25519.PublicKey)
+		sig := ed25519.Sign(s.Key, buf.Bytes())
+		fmt.Fprintf(&buf, "%s%s %s\n", signaturePrefix,
+			base64.StdEncoding.EncodeToString(pub),
+			base64.StdEncoding.EncodeToString(sig))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// VerifyReport checks the signature at the end of a report written by
+// WriteSigned, and returns the public key that signed it. Callers must
+// check that the key is one they trust.
+func VerifyReport(report []byte) (ed25519.PublicKey, error) {
+	body := bytes.TrimSuffix(report, []byte("\n"))
+	i := bytes.LastIndexByte(body, '\n')
+	if i < 0 || !bytes.HasPrefix(body[i+1:], []byte(signaturePrefix)) {
+		return nil, errors.New("report is not signed")
+	}
+	body, line := report[:i+1], string(body[i+1+len(signaturePrefix):])
+	pubText, sigText, _ := strings.Cut(line, " ")
+	pub, err := base64.StdEncoding.DecodeString(pubText)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key in signature")
+	}
+	sig, err := base64.StdEncoding.DecodeString(sigText)
+	if err != nil {
+		return nil, errors.New("invalid signature")
+	}
+	if !ed25519.Verify(pub, body, sig) {
+		return nil, errors.New("signature does not match the report")
+	}
+	return pub, nil
+}
+
+// Run runs the self-test against the embedded corpus.
+func Run() (*Report, error) {
+	return RunCorpus(corpus)
+}
+
+// RunCorpus runs the self-test against the given JSON-encoded corpus.
+func RunCorpus(data []byte) (*Report, error) {
+	var c Corpus
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid corpus: %v", err)
+	}
+	if c.Version != CorpusVersion {
+		return nil, fmt.Errorf("unsupported corpus version %d", c.Version)
+	}
+	report := &Report{
+		CorpusVersion: c.Version,
+		CorpusDigest:  fmt.Sprintf("%x", sha256.Sum256(data)),
+		FIPS:          crypt.CurrentFIPSStatus(),
+	}
+	add := func(check, name, note string, err error) {
+		// Refusing to do something the crypto policy forbids is the
+		// correct behaviour.
+		if errors.Is(err, crypt.ErrPolicy) {
+			note, err = "refused by policy", nil
+		}
+		report.Results = append(report.Results, Result{check, name, note, err})
+	}
+
+	names := make([]string, 0, len(c.Keys))
+	for name := range c.Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ciphers := make(map[string]crypt.Cipher, len(c.Keys))
+	for _, name := range names {
+		def := c.Keys[name]
+		cipher, err := crypt.LoadCipher(def.Mode, strings.NewReader(def.Key))
+		if err == nil && cipher.Fingerprint() != def.Fingerprint {
+			err = fmt.Errorf("fingerprint mismatch: got %s", cipher.Fingerprint())
+		}
+		add("fingerprint", name, "", err)
+		if err == nil {
+			ciphers[name] = cipher
+		}
+	}
+
+	for _, v := range c.Vectors {
+		cipher, ok := ciphers[v.Key]
+		if _, known := c.Keys[v.Key]; !known {
+			add("decrypt", v.Name, "", fmt.Errorf("unknown key %q", v.Key))
+			continue
+		} else if !ok {
+			add("decrypt", v.Name, "", fmt.Errorf("key %q failed", v.Key))
+			continue
+		}
+		note, err := checkDecrypt(cipher, v)
+		add("decrypt", v.Name, note, err)
+		if note, ok, err := checkEncrypt(c.Keys[v.Key], v); ok {
+			add("encrypt", v.Name, note, err)
+		}
+	}
+
+	// Round trips between every registered mode that can use each key,
+	// since some modes share key material.
+	for _, name := range names {
+		def := c.Keys[name]
+		var compatible []crypt.Cipher
+		for _, m := range crypt.Modes() {
+			if !sameFamily(def.Mode, m.Name) {
+				continue
+			}
+			cipher, err := crypt.LoadCipher(m.Name, strings.NewReader(def.Key))
+			if err != nil {
+				add("roundtrip", name+"/"+m.Name, "", err)
+				continue
+			}
+			compatible = append(compatible, cipher)
+		}
+		for _, a := range compatible {
+			for _, b := range compatible {
+				add("roundtrip", fmt.Sprintf("%s/%s->%s", name, a.Mode(), b.Mode()),
+					"", roundTrip(a, b))
+			}
+		}
+	}
+
+	// Modes not covered by the corpus still get a round trip with a
+	// throwaway key.
+	for _, m := range crypt.Modes() {
+		covered := false
+		for _, def := range c.Keys {
+			covered = covered || sameFamily(def.Mode, m.Name)
+		}
+		if covered {
+			continue
+		}
+		key, _ := crypt.NewKey()
+		cipher, err := crypt.LoadCipher(m.Name, strings.NewReader(key.HexString()))
+		if err == nil {
+			err = roundTrip(cipher, cipher)
+		}
+		add("roundtrip", "generated/"+m.Name, "", err)
+	}
+	return report, nil
+}
+
+// sameFamily reports whether two modes use the same key format and can decrypt
+// each other's output.
+func sameFamily(a, b string) bool {
+	family := func(mode string) string {
+		if mode == "fips" {
+			return "default"
+		}
+		return mode
+	}
+	return family(a) == family(b)
+}
+
+func checkDecrypt(cipher crypt.Cipher, v Vector) (string, error) {
+	text, err := cipher.Decrypt(v.Ciphertext)
+	if errors.Is(err, crypt.ErrPolicy) {
+		return "", err
+	}
+	if isSecretbox(v.Algorithm) && crypt.FIPSEnabled() {
+		// The correct behaviour in FIPS mode is to refuse.
+		if errors.Is(err, crypt.ErrFIPS) {
+			return "refused in FIPS mode", nil
+		}
+		return "", fmt.Errorf("expected %v, got %v", crypt.ErrFIPS, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if text != v.Plaintext {
+		return "", errors.New("plain text mismatch")
+	}
+	return "", nil
+}
+
+// checkEncrypt reproduces the cipher text of a vector using the nonce it
+// contains, for algorithms that allow this. ok is false if the vector cannot be
+// checked this way.
+func checkEncrypt(def KeyDef, v Vector) (note string, ok bool, err error) {
+	if def.Mode == "workbench" {
+		return checkEncryptWorkbench(def, v)
+	}
+	if !sameFamily(def.Mode, "default") {
+		return "", false, nil
+	}
+	key, err := crypt.NewKeyFromBytes([]byte(def.Key))
+	if err != nil {
+		return "", true, err
+	}
+	buf, err := base64.StdEncoding.DecodeString(v.Ciphertext)
+	if err != nil {
+		return "", true, err
+	}
+	var prefix []byte
+	switch v.Algorithm {
+	case "secretbox-v1":
+		// Copy the prefix, since the output is appended to it.
+		prefix, buf = []byte{buf[0]}, buf[1:]
+		fallthrough
+	case "secretbox":
+		aead, err := key.SecretboxAEAD()
+		if errors.Is(err, crypt.ErrFIPS) {
+			return "refused in FIPS mode", true, nil
+		} else if err != nil {
+			return "", true, err
+		} else if len(buf) < aead.NonceSize() {
+			return "", true, errors.New("invalid vector")
+		}
+		nonce := buf[:aead.NonceSize()]
+		out := aead.Seal(append(prefix, nonce...), nonce, []byte(v.Plaintext), nil)
+		return "", true, compare(out, v.Ciphertext)
+	case "aes-256-gcm-v2":
+		aead, err := key.AEAD()
+		if err != nil {
+			return "", true, err
+		}
+		if len(buf) < 1+aead.NonceSize() {
+			return "", true, errors.New("invalid vector")
+		}
+		nonce := buf[1 : 1+aead.NonceSize()]
+		out := aead.Seal(append([]byte{2}, nonce...), nonce, []byte(v.Plaintext), nil)
+		return "", true, compare(out, v.Ciphertext)
+	}
+	return "", false, nil
+}
+
+// checkEncryptWorkbench reproduces the cipher text of a Workbench vector using
+// the IV it contains. Only the first 16 bytes of the 32-byte IV prefix are
+// used; rstudio-server fills the rest with random bytes, and rskey with zeros,
+// so those are taken from the vector.
+func checkEncryptWorkbench(def KeyDef, v Vector) (note string, ok bool, err error) {
+	if v.Algorithm != "aes-128-cbc-workbench" {
+		return "", false, nil
+	}
+	key, err := workbench.NewKeyFromBytes([]byte(def.Key))
+	if err != nil {
+		return "", true, err
+	}
+	const checksumLength = 8
+	if len(v.Ciphertext) < 2*checksumLength {
+		return "", true, errors.New("invalid vector")
+	}
+	want, err := base64.StdEncoding.DecodeString(v.Ciphertext[checksumLength : len(v.Ciphertext)-checksumLength])
+	if err != nil || len(want) < 32 {
+		return "", true, fmt.Errorf("invalid vector: %v", err)
+	}
+	ciphertext, err := key.WithRand(bytes.NewReader(want[:16])).Encrypt(v.Plaintext)
+	if err != nil {
+		return "", true, err
+	}
+	if len(ciphertext) < 2*checksumLength || ciphertext[:checksumLength] != v.Ciphertext[:checksumLength] {
+		return "", true, errors.New("checksum mismatch")
+	}
+	out, err := base64.StdEncoding.DecodeString(ciphertext[checksumLength : len(ciphertext)-checksumLength])
+	if err != nil || len(out) < 32 {
+		return "", true, fmt.Errorf("invalid output: %v", err)
+	}
+	copy(out[16:32], want[16:32])
+	return "", true, compare(out, base64.StdEncoding.EncodeToString(want))
+}
+
+func compare(out []byte, want string) error {
+	if base64.StdEncoding.EncodeToString(out) != want {
+		return errors.New("cipher text mismatch")
+	}
+	return nil
+}
+
+func isSecretbox(algorithm string) bool {
+	return strings.HasPrefix(algorithm, "secretbox")
+}
+
+func roundTrip(a, b crypt.Cipher) error {
+	const text = "Posit rskey round-trip test"
+	cipher, err := a.Encrypt(text)
+	if err != nil {
+		return err
+	}
+	out, err := b.Decrypt(cipher)
+	if err != nil {
+		return err
+	}
+	if out != text {
+		return errors.New("plain text mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package selftest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+type SelftestSuite struct{}
+
+func (s *SelftestSuite) TestRun(c *check.C) {
+	report, err := Run()
+	c.Assert(err, check.IsNil)
+	c.Check(report.CorpusVersion, check.Equals, CorpusVersion)
+	c.Check(report.Failures(), check.Equals, 0)
+	checks := map[string]int{}
+	for _, res := range report.Results {
+		c.Check(res.Err, check.IsNil, check.Commentf("%s %s", res.Check, res.Name))
+		checks[res.Check]++
+	}
+	c.Check(checks["fingerprint"], check.Equals, 2)
+	c.Check(checks["decrypt"], check.Equals, 9)
+	c.Check(checks["encrypt"], check.Equals, 9)
+	// default and fips are pairwise, plus workbench with itself.
+	c.Check(checks["roundtrip"], check.Equals, 5)
+
+	var buf bytes.Buffer
+	c.Check(report.Write(&buf, ""), check.IsNil)
+	c.Check(buf.String(), check.Matches, `(?s)Corpus: +version 1, sha256 [0-9a-f]{64}\n.*`+
+		`Result: PASS \(25 checks, 0 failures\)\nReport sha256: [0-9a-f]{64}\n`)
+}
+
+func (s *SelftestSuite) TestSignOff(c *check.C) {
+	report, err := Run()
+	c.Assert(err, check.IsNil)
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	pub := key.Public()
+
+	var buf bytes.Buffer
+	c.Assert(report.WriteSigned(&buf, "", SignOff{Name: "Jane Doe <jane@example.com>", Key: key}), check.IsNil)
+	c.Check(buf.String(), check.Matches, `(?s).*\nSigned-off-by: Jane Doe <jane@example.com>\n`+
+		`Report sha256: [0-9a-f]{64}\nSignature: ed25519 \S+ \S+\n`)
+	signer, err := VerifyReport(buf.Bytes())
+	c.Assert(err, check.IsNil)
+	c.Check(signer, check.DeepEquals, pub)
+
+	tampered := bytes.Replace(buf.Bytes(), []byte("Jane"), []byte("John"), 1)
+	_, err = VerifyReport(tampered)
+	c.Check(err, check.ErrorMatches, `signature does not match the report`)
+
+	buf.Reset()
+	c.Assert(report.Write(&buf, ""), check.IsNil)
+	_, err = VerifyReport(buf.Bytes())
+	c.Check(err, check.ErrorMatches, `report is not signed`)
+}
+
+func (s *SelftestSuite) TestFIPS(c *check.C) {
+	crypt.SetFIPSPolicy(crypt.FIPSEnforce)
+	defer crypt.SetFIPSPolicy(crypt.FIPSAuto)
+	report, err := Run()
+	c.Assert(err, check.IsNil)
+	c.Check(report.Failures(), check.Equals, 0)
+	refused := 0
+	for _, res := range report.Results {
+		if res.Note == "refused in FIPS mode" {
+			refused++
+		}
+	}
+	c.Check(refused, check.Equals, 8)
+}
+
+func (s *SelftestSuite) TestPolicy(c *check.C) {
+	c.Assert(crypt.SetPolicy(&crypt.Policy{
+		EncryptAlgorithms: []string{crypt.AlgorithmAESGCM},
+		DecryptFormats:    []string{crypt.FormatV2},
+	}), check.IsNil)
+	defer crypt.SetPolicy(nil)
+	report, err := Run()
+	c.Assert(err, check.IsNil)
+	c.Check(report.Failures(), check.Equals, 0)
+	refused := 0
+	for _, res := range report.Results {
+		if res.Note == "refused by policy" {
+			refused++
+		}
+	}
+	// The four secretbox vectors can no longer be decrypted, and the
+	// Workbench vectors and round trip can no longer encrypt. Outside FIPS
+	// mode, the secretbox vectors can no longer encrypt either.
+	want := 8
+	if !crypt.FIPSEnabled() {
+		want += 4
+	}
+	c.Check(refused, check.Equals, want)
+}
+
+func (s *SelftestSuite) TestMismatch(c *check.C) {
+	// Change a single plain text.
+	tampered := strings.Replace(string(corpus),
+		"\"aes-256-gcm-v2\",\n      \"plaintext\": \"success\"",
+		"\"aes-256-gcm-v2\",\n      \"plaintext\": \"failure\"", 1)
+	report, err := RunCorpus([]byte(tampered))
+	c.Assert(err, check.IsNil)
+	c.Check(report.Failures(), check.Equals, 2)
+
+	var buf bytes.Buffer
+	c.Check(report.Write(&buf, ""), check.IsNil)
+	c.Check(buf.String(), check.Matches, `(?s).*FAIL  decrypt     aes-256-gcm-v2 \(plain text mismatch\)\n`+
+		`FAIL  encrypt     aes-256-gcm-v2 \(cipher text mismatch\)\n.*Result: FAIL.*`)
+
+	// Change a fingerprint, which also fails that key's vectors.
+	tampered = strings.Replace(string(corpus), `"BFA25145"`, `"BFA25146"`, 1)
+	report, err = RunCorpus([]byte(tampered))
+	c.Assert(err, check.IsNil)
+	c.Check(report.Failures(), check.Equals, 4)
+	c.Check(report.Results[1].Err, check.ErrorMatches, `fingerprint mismatch: got BFA25145`)
+	c.Check(report.Results[len(report.Results)-6].Err, check.ErrorMatches, `key "workbench" failed`)
+
+	_, err = RunCorpus([]byte(`{"version": 2}`))
+	c.Check(err, check.ErrorMatches, `unsupported corpus version 2`)
+	_, err = RunCorpus([]byte(`not json`))
+	c.Check(err, check.ErrorMatches, `invalid corpus: .+`)
+}
+
+func Test(t *testing.T) {
+	_ = check.Suite(&SelftestSuite{})
+	check.TestingT(t)
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package workbench
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severity is how much a Finding weakens a key.
+type Severity string
+
+const (
+	// Info findings do not weaken the key.
+	Info Severity = "info"
+	// Warning findings weaken the key, but not enough to reject it.
+	Warning Severity = "warning"
+	// Weak findings mean the key should be replaced.
+	Weak Severity = "weak"
+)
+
+// minEntropy is the estimated entropy, in bits, below which a key is weak.
+const minEntropy = 64
+
+// Finding is a single observation about a key.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	// Code is a stable identifier for the kind of finding, such as
+	// "uuid-v1".
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Analysis describes the strength of a Workbench key.
+type Analysis struct {
+	// Length is the length of the key in bytes, ignoring trailing
+	// whitespace.
+	Length int `json:"length"`
+	// Entropy is the estimate from EstimateEntropy(), in bits.
+	Entropy float64 `json:"entropy"`
+	// Distinct is the number of distinct bytes in the key.
+	Distinct int `json:"distinct"`
+	// UUIDVersion is the version of a key that is a UUID literal, or 0.
+	UUIDVersion int       `json:"uuid_version,omitempty"`
+	Findings    []Finding `json:"findings"`
+}
+
+// Weak reports whether any finding means the key should be replaced.
+func (a *Analysis) Weak() bool {
+	for _, f := range a.Findings {
+		if f.Severity == Weak {
+			return true
+		}
+	}
+	return false
+}
+
+var uuidPattern = regexp.MustCompile(
+	`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-([0-9a-fA-F])[0-9a-fA-F]{3}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Analyze estimates the strength of a Workbench key, given the contents of a
+// key file. It recognises UUID literals and flags those that are not random,
+// as well as keys that are short, predictable, or made of few distinct
+// characters or a repeated pattern.
+func Analyze(data []byte) *Analysis {
+	key := bytes.TrimRight(data, " \t\r\n")
+	a := &Analysis{
+		Length:   len(key),
+		Entropy:  EstimateEntropy(key),
+		Findings: []Finding{},
+	}
+	add := func(severity Severity, code, format string, args ...any) {
+		a.Findings = append(a.Findings, Finding{severity, code, fmt.Sprintf(format, args...)})
+	}
+	var seen [256]bool
+	for _, b := range key {
+		if !seen[b] {
+			seen[b] = true
+			a.Distinct++
+		}
+	}
+
+	if a.Length < minKeyLength {
+		add(Weak, "short", "key is %d bytes, but Workbench requires at least %d", a.Length, minKeyLength)
+	}
+	if m := uuidPattern.FindSubmatch(key); m != nil {
+		fmt.Sscanf(string(m[1]), "%x", &a.UUIDVersion)
+		analyzeUUID(a, key, add)
+	}
+	if a.Entropy < minEntropy {
+		add(Weak, "low-entropy", "estimated entropy is %.0f bits, below %d", a.Entropy, minEntropy)
+	}
+	if a.Length > 0 && a.Distinct < 10 {
+		add(Weak, "low-diversity", "key uses only %d distinct characters", a.Distinct)
+	}
+	if period := repeatPeriod(key); period > 0 {
+		add(Weak, "repeated", "key repeats a %d-byte pattern", period)
+	}
+	if sequential(key) {
+		add(Weak, "sequential", "key is mostly a sequence of consecutive characters")
+	}
+	words := phraseWords(string(key))
+	if words != nil {
+		add(Weak, "phrase", "key looks like a phrase of %d words, which is far easier to guess than random characters",
+			len(words))
+	}
+	// Keys that repeat a pattern throughout are already reported.
+	if len(key) <= maxPatternLength && repeatPeriod(key) == 0 {
+		p := parsePattern(key)
+		// Repeats of common words count as common words too.
+		common := p.cover(wordToken)
+		if words == nil && len(common) > 0 && (p.length(wordToken)+p.length(repeatToken))*2 >= len(key) {
+			add(Warning, "dictionary", "key is mostly made of common words or passwords, such as %q", common[0])
+		}
+		if p.length(repeatToken)*4 >= len(key) {
+			add(Warning, "repeated-substring", "key repeats parts of itself, such as %q", p.cover(repeatToken)[0])
+		}
+	}
+	return a
+}
+
+// cover returns the text of the tokens of a kind.
+func (p pattern) cover(kind tokenKind) []string {
+	var out []string
+	for _, t := range p.tokens {
+		if t.kind == kind {
+			out = append(out, t.text)
+		}
+	}
+	return out
+}
+
+// length returns the total length of the tokens of a kind.
+func (p pattern) length(kind tokenKind) int {
+	n := 0
+	for _, t := range p.cover(kind) {
+		n += len(t)
+	}
+	return n
+}
+
+func analyzeUUID(a *Analysis, key []byte, add func(Severity, string, string, ...any)) {
+	if strings.Trim(string(key), "0-") == "" {
+		a.UUIDVersion = 0
+		add(Weak, "uuid-nil", "key is the nil UUID")
+		return
+	}
+	switch a.UUIDVersion {
+	case 1, 2, 6:
+		add(Weak, fmt.Sprintf("uuid-v%d", a.UUIDVersion),
+			"key is a time-based version %d UUID, which reveals the MAC address and time it was generated",
+			a.UUIDVersion)
+	case 3, 5:
+		add(Weak, fmt.Sprintf("uuid-v%d", a.UUIDVersion),
+			"key is a name-based version %d UUID, which anyone who knows the name can reproduce",
+			a.UUIDVersion)
+	case 4:
+		add(Info, "uuid-v4", "key is a random version 4 UUID with 122 random bits")
+	case 7:
+		add(Warning, "uuid-v7",
+			"key is a time-ordered version 7 UUID, which reveals the time it was generated and has only 74 random bits")
+	default:
+		add(Warning, "uuid-unknown", "key looks like a UUID with unknown version %d", a.UUIDVersion)
+	}
+}
+
+// repeatPeriod returns the length of the shortest pattern that the key
+// repeats at least twice, or 0 if there is none.
+func repeatPeriod(key []byte) int {
+	for period := 1; period <= len(key)/2; period++ {
+		repeats := true
+		for i := period; i < len(key) && repeats; i++ {
+			repeats = key[i] == key[i-period]
+		}
+		if repeats {
+			return period
+		}
+	}
+	return 0
+}
+
+// sequential reports whether most adjacent bytes in the key are consecutive,
+// as in "abcdef" or "987654".
+func sequential(key []byte) bool {
+	if len(key) < 2 {
+		return false
+	}
+	steps := 0
+	for i := 1; i < len(key); i++ {
+		if d := int(key[i]) - int(key[i-1]); d == 1 || d == -1 {
+			steps++
+		}
+	}
+	return steps*2 > len(key)-1
+}
+
+// phraseWords returns the words of a key that looks like a phrase, such as
+// "correct horse battery staple", "correct-horse-battery-staple",
+// "CorrectHorseBatteryStaple", or "correcthorsebatterystaple", or nil if it
+// does not. Words are separated by spaces, punctuation, or a change from lower
+// to upper case, and runs of letters made entirely of dictionary words are
+// split into those words. A phrase has at least three words made only of
+// letters, averaging three or more letters each. Random mixed-case keys split
+// into much shorter pieces.
+func phraseWords(key string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(key, func(r rune) bool {
+		return strings.ContainsRune(" \t-_.,;:!?'\"+", r)
+	}) {
+		start := 0
+		for i := 1; i <= len(part); i++ {
+			if i == len(part) || isLower(part[i-1]) && isUpper(part[i]) {
+				words = append(words, splitWords(part[start:i])...)
+				start = i
+			}
+		}
+	}
+	if len(words) < 3 {
+		return nil
+	}
+	letters := 0
+	for _, w := range words {
+		for i := 0; i < len(w); i++ {
+			if !isLower(w[i]) && !isUpper(w[i]) {
+				return nil
+			}
+		}
+		letters += len(w)
+	}
+	if letters < 3*len(words) {
+		return nil
+	}
+	return words
+}
+
+// splitWords splits s into dictionary words, using as few as possible, or
+// returns s alone if it cannot be.
+func splitWords(s string) []string {
+	lower := strings.ToLower(s)
+	// split[i] holds the fewest words that lower[:i] splits into.
+	split := make([][]string, len(s)+1)
+	split[0] = []string{}
+	for end := 1; end <= len(s); end++ {
+		for start := max(0, end-maxWordLength); start <= end-3; start++ {
+			if split[start] != nil && dictionary[lower[start:end]] &&
+				(split[end] == nil || len(split[start])+1 < len(split[end])) {
+				split[end] = append(append([]string{}, split[start]...), s[start:end])
+			}
+		}
+	}
+	if split[len(s)] == nil {
+		return []string{s}
+	}
+	return split[len(s)]
+}
+
+func isLower(b byte) bool { return b >= 'a' && b <= 'z' }
+
+func isUpper(b byte) bool { return b >= 'A' && b <= 'Z' }
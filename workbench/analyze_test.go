@@ -0,0 +1,73 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package workbench
+
+import (
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+func codes(a *Analysis) []string {
+	out := []string{}
+	for _, f := range a.Findings {
+		out = append(out, f.Code)
+	}
+	return out
+}
+
+func (s *WorkbenchSuite) TestAnalyze(c *check.C) {
+	hex, _ := crypt.NewKey()
+	cases := []struct {
+		key   string
+		weak  bool
+		codes []string
+		uuid  int
+	}{
+		{"5a40aeab-ecba-4632-9499-0fc7fc300b78\n", false, []string{"uuid-v4"}, 4},
+		{hex.HexString(), false, []string{}, 0},
+		{"c232ab00-9414-11ec-b3c8-9f6bdeced846\n", true, []string{"uuid-v1"}, 1},
+		{"886313e1-3b8a-5372-9b90-0c9aee199e5d", true, []string{"uuid-v5"}, 5},
+		{"018f3c4e-7a2b-7c3d-8e4f-5a6b7c8d9e0f", false, []string{"uuid-v7"}, 7},
+		{"00000000-0000-0000-0000-000000000000", true,
+			[]string{"uuid-nil", "low-entropy", "low-diversity", "repeated-substring"}, 0},
+		{strings.Repeat("a", 32), true,
+			[]string{"low-entropy", "low-diversity", "repeated"}, 0},
+		{strings.Repeat("abc123", 6), true,
+			[]string{"low-entropy", "low-diversity", "repeated", "sequential"}, 0},
+		{"abcdefghijklmnopqrstuvwxyzABCDEF", true, []string{"sequential"}, 0},
+		{"correct horse battery staple again", true, []string{"phrase"}, 0},
+		{"correct-horse-battery-staple-again", true, []string{"phrase"}, 0},
+		{"CorrectHorseBatteryStapleAgainNow", true, []string{"phrase"}, 0},
+		{"qZxWvbnRtYuPlkJhGfDsAmNbVcXzQwEr", false, []string{}, 0},
+		{"too short", true, []string{"short", "low-entropy", "low-diversity", "dictionary"}, 0},
+		{"correcthorsebatterystaplecorrecthorse", true, []string{"low-entropy", "phrase", "repeated-substring"}, 0},
+		{"passwordpasswordletmeinletmein12", true,
+			[]string{"low-entropy", "dictionary", "repeated-substring"}, 0},
+		{"Sunshine-2024!Sunshine-2024!xQ9#", true,
+			[]string{"low-entropy", "dictionary", "repeated-substring"}, 0},
+	}
+	for _, tc := range cases {
+		comment := check.Commentf("%q", tc.key)
+		a := Analyze([]byte(tc.key))
+		c.Check(a.Weak(), check.Equals, tc.weak, comment)
+		c.Check(codes(a), check.DeepEquals, tc.codes, comment)
+		c.Check(a.UUIDVersion, check.Equals, tc.uuid, comment)
+	}
+	a := Analyze([]byte("5a40aeab-ecba-4632-9499-0fc7fc300b78\n"))
+	c.Check(a.Length, check.Equals, 36)
+	c.Check(a.Distinct, check.Equals, 15)
+}
+
+func (s *WorkbenchSuite) TestRejectWeakKeys(c *check.C) {
+	defer crypt.SetPolicy(nil)
+	c.Assert(crypt.SetPolicy(&crypt.Policy{RejectWeakWorkbenchKeys: true}), check.IsNil)
+	_, err := NewKeyFromBytes([]byte("5a40aeab-ecba-4632-9499-0fc7fc300b78\n"))
+	c.Check(err, check.IsNil)
+	_, err = NewKeyFromBytes([]byte("c232ab00-9414-11ec-b3c8-9f6bdeced846\n"))
+	c.Check(err, check.ErrorMatches,
+		`.*\(reject_weak_workbench_keys\): Workbench key is weak: key is a time-based version 1 UUID.*`)
+}
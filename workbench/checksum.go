@@ -0,0 +1,45 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package workbench
+
+import (
+	"crypto/aes"
+	"encoding/base64"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+// Checksum returns the key checksum embedded in cipher text produced by
+// Encrypt. It identifies the key used to encrypt the value, and matches that
+// key's Fingerprint().
+func Checksum(s string) (string, error) {
+	if len(s) < minPayloadLength {
+		return "", crypt.ErrPayLoadTooShort
+	}
+	// The checksum is embedded in the payload -- twice.
+	if s[:8] != s[len(s)-8:] || !isChecksum(s[:8]) {
+		return "", ErrMissingChecksum
+	}
+	return s[:8], nil
+}
+
+// IsEncrypted reports whether s looks like cipher text produced by Encrypt,
+// with any key.
+func IsEncrypted(s string) bool {
+	if _, err := Checksum(s); err != nil {
+		return false
+	}
+	buf, err := base64.StdEncoding.DecodeString(s[8 : len(s)-8])
+	return err == nil && len(buf) >= 32+aes.BlockSize && len(buf)%aes.BlockSize == 0
+}
+
+// isChecksum reports whether s is formatted like the output of crc32HexHash().
+func isChecksum(s string) bool {
+	for _, c := range []byte(s) {
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return len(s) == 8
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package workbench
+
+import (
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+func (s *WorkbenchSuite) TestChecksum(c *check.C) {
+	key, err := NewKeyFromBytes([]byte(sampleKey))
+	c.Assert(err, check.IsNil)
+	ciphertext, err := key.Encrypt("hunter2")
+	c.Assert(err, check.IsNil)
+
+	sum, err := Checksum(ciphertext)
+	c.Check(err, check.IsNil)
+	c.Check(sum, check.Equals, key.Fingerprint())
+	c.Check(IsEncrypted(ciphertext), check.Equals, true)
+
+	_, err = Checksum("short")
+	c.Check(err, check.Equals, crypt.ErrPayLoadTooShort)
+	_, err = Checksum("00000000" + ciphertext[8:])
+	c.Check(err, check.Equals, ErrMissingChecksum)
+	_, err = Checksum("postgresql://user@host:5432/database?sslmode=require")
+	c.Check(err, check.Equals, ErrMissingChecksum)
+	c.Check(IsEncrypted("postgresql"), check.Equals, false)
+	c.Check(IsEncrypted(sum+"not base64 at all, but long enough"+sum), check.Equals, false)
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package workbench
+
+import (
+	"bytes"
+	_ "embed"
+	"math"
+	"strings"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+// EstimateEntropy returns a rough estimate of the entropy of a key in bits,
+// based on the frequency of each byte in it. A random UUID scores about 140,
+// while a key made of one repeated character scores 0. Keys that are made of
+// common words or repeat parts of themselves are capped at the cost of
+// guessing them that way, so "passwordpassword" scores far less than its
+// letters suggest.
+func EstimateEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	bits := frequencyEntropy(data)
+	if len(data) <= maxPatternLength {
+		if p := parsePattern(data); p.bits < bits {
+			bits = p.bits
+		}
+	}
+	return bits
+}
+
+// frequencyEntropy estimates the entropy of data from the frequency of each
+// byte in it.
+func frequencyEntropy(data []byte) float64 {
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	perByte := 0.0
+	for _, n := range counts {
+		if n == 0 {
+			continue
+		}
+		p := float64(n) / float64(len(data))
+		perByte -= p * math.Log2(p)
+	}
+	return perByte * float64(len(data))
+}
+
+// maxPatternLength is the length of the longest key that parsePattern is used
+// for. Longer keys are not made by hand, and would take too long to parse.
+const maxPatternLength = 256
+
+//go:embed words.txt
+var wordList string
+
+// dictionary holds common words and passwords, which are the first things
+// tried when guessing a key.
+var dictionary = func() map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.Fields(wordList) {
+		words[w] = true
+	}
+	return words
+}()
+
+// maxWordLength is the length of the longest word in the dictionary.
+var maxWordLength = func() int {
+	n := 0
+	for w := range dictionary {
+		n = max(n, len(w))
+	}
+	return n
+}()
+
+// tokenKind is the kind of a token in a pattern.
+type tokenKind int
+
+const (
+	charToken tokenKind = iota
+	wordToken
+	repeatToken
+)
+
+// pattern describes a key as a sequence of tokens.
+type pattern struct {
+	// The estimated cost of guessing the key this way, in bits.
+	bits   float64
+	tokens []token
+}
+
+// token is a single character, a dictionary word, or a repeat of an earlier
+// part of a key.
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// charBits is the cost of guessing a single character, from the size of its
+// class.
+func charBits(b byte) float64 {
+	switch {
+	case b >= '0' && b <= '9':
+		return math.Log2(10)
+	case isLower(b) || isUpper(b):
+		return math.Log2(26)
+	}
+	return math.Log2(33)
+}
+
+// parsePattern finds the cheapest description of a key as a sequence of single
+// characters, dictionary words (in any case), and repeats of earlier parts of
+// the key, where a repeat costs only its position and length.
+func parsePattern(key []byte) pattern {
+	wordBits := math.Log2(float64(len(dictionary)))
+	type step struct {
+		bits  float64
+		start int
+		kind  tokenKind
+	}
+	best := make([]step, len(key)+1)
+	for i := 1; i <= len(key); i++ {
+		best[i].bits = math.Inf(1)
+	}
+	lower := bytes.ToLower(key)
+	for i := 0; i < len(key); i++ {
+		try := func(end int, kind tokenKind, bits float64) {
+			if bits += best[i].bits; bits < best[end].bits {
+				best[end] = step{bits, i, kind}
+			}
+		}
+		try(i+1, charToken, charBits(key[i]))
+		repeats := true
+		for end := i + 3; end <= len(key); end++ {
+			if end-i <= maxWordLength && dictionary[string(lower[i:end])] {
+				bits := wordBits
+				if !bytes.Equal(key[i:end], lower[i:end]) {
+					// Capitalized or upper case.
+					bits++
+				}
+				try(end, wordToken, bits)
+			}
+			// Longer parts cannot repeat if shorter ones do not.
+			if repeats = repeats && bytes.Contains(key[:i], key[i:end]); repeats {
+				try(end, repeatToken, math.Log2(float64(i))+math.Log2(float64(end-i)))
+			}
+		}
+	}
+	p := pattern{bits: best[len(key)].bits}
+	for end := len(key); end > 0; end = best[end].start {
+		s := best[end]
+		p.tokens = append([]token{{s.kind, string(key[s.start:end])}}, p.tokens...)
+	}
+	return p
+}
+
+// checkPolicy applies the current crypt.Policy to a key, given its original
+// bytes.
+func checkPolicy(src []byte, hash string) error {
+	p := crypt.CurrentPolicy()
+	if err := p.AllowsKey(hash); err != nil {
+		return err
+	}
+	if err := p.AllowsWorkbenchKey(len(src), EstimateEntropy(src)); err != nil {
+		return err
+	}
+	if p != nil && p.RejectWeakWorkbenchKeys {
+		for _, f := range Analyze(src).Findings {
+			if f.Severity == Weak {
+				return &crypt.PolicyError{Rule: "reject_weak_workbench_keys",
+					Reason: "Workbench key is weak: " + f.Message}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package workbench
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
+)
+
+func (s *WorkbenchSuite) TestEstimateEntropy(c *check.C) {
+	c.Check(EstimateEntropy(nil), check.Equals, 0.0)
+	c.Check(EstimateEntropy(bytes.Repeat([]byte("a"), 64)), check.Equals, 0.0)
+	c.Check(EstimateEntropy([]byte("abababab")), check.Equals, 8.0)
+	uuid := EstimateEntropy([]byte("5a40aeab-ecba-4632-9499-0fc7fc300b78\n"))
+	c.Check(uuid > 130 && uuid < 150, check.Equals, true, check.Commentf("%f", uuid))
+	// Common words and repeats are far easier to guess than their letters
+	// suggest.
+	for _, key := range []string{"correcthorsebatterystaplecorrecthorse", "passwordpasswordletmeinletmein12"} {
+		bits := EstimateEntropy([]byte(key))
+		c.Check(bits < 64, check.Equals, true, check.Commentf("%s: %f", key, bits))
+	}
+	random := EstimateEntropy([]byte("qZxWvbnRtYuPlkJhGfDsAmNbVcXzQwEr"))
+	c.Check(random > 120, check.Equals, true, check.Commentf("%f", random))
+}
+
+func (s *WorkbenchSuite) TestPolicy(c *check.C) {
+	defer crypt.SetPolicy(nil)
+	uuid := []byte("5a40aeab-ecba-4632-9499-0fc7fc300b78\n")
+	key, err := NewKeyFromBytes(uuid)
+	c.Assert(err, check.IsNil)
+	cipher, err := key.Encrypt("some secret")
+	c.Assert(err, check.IsNil)
+
+	c.Assert(crypt.SetPolicy(&crypt.Policy{
+		MinWorkbenchKeyLength:  36,
+		MinWorkbenchKeyEntropy: 100,
+	}), check.IsNil)
+	_, err = NewKeyFromBytes(uuid)
+	c.Check(err, check.IsNil)
+	_, err = NewKeyFromBytes(bytes.Repeat([]byte("a"), 40))
+	c.Check(err, check.ErrorMatches,
+		`.*\(min_workbench_key_entropy\): Workbench key has an estimated 0 bits of entropy, but must have at least 100`)
+	_, err = NewKeyFromBytes([]byte("0123456789abcdef0123456789abcdef"))
+	c.Check(err, check.ErrorMatches,
+		`.*\(min_workbench_key_length\): Workbench key is 32 bytes, but must be at least 36`)
+
+	c.Assert(crypt.SetPolicy(&crypt.Policy{
+		EncryptAlgorithms: []string{crypt.AlgorithmAESGCM},
+	}), check.IsNil)
+	_, err = key.Encrypt("some secret")
+	c.Check(errors.Is(err, crypt.ErrPolicy), check.Equals, true)
+	text, err := key.Decrypt(cipher)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "some secret")
+
+	c.Assert(crypt.SetPolicy(&crypt.Policy{
+		RevokedFingerprints: []string{strings.ToLower(key.Fingerprint())},
+	}), check.IsNil)
+	_, err = NewKeyFromBytes(uuid)
+	c.Check(err, check.ErrorMatches, `.*key `+key.Fingerprint()+` has been revoked`)
+	_, err = key.Decrypt(cipher)
+	c.Check(errors.Is(err, crypt.ErrPolicy), check.Equals, true)
+}
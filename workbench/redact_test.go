@@ -0,0 +1,56 @@
+// Copyright 2026 Posit Software, PBC
+// SPDX-License-Identifier: Apache-2.0
+
+package workbench
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"gopkg.in/check.v1"
+)
+
+func (s *WorkbenchSuite) TestRedaction(c *check.C) {
+	key, _ := NewKeyFromBytes([]byte(sampleKey))
+	redacted := "workbench.Key(" + sampleHash + ")"
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%x", "%q"} {
+		c.Check(fmt.Sprintf(verb, key), check.Equals, redacted)
+		c.Check(fmt.Sprintf(verb, *key), check.Equals, redacted)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("loaded", "key", key)
+	c.Check(buf.String(), check.Matches, `.*key=`+regexp.QuoteMeta(redacted)+`\n`)
+}
+
+func (s *WorkbenchSuite) TestTextMarshaling(c *check.C) {
+	key, _ := NewKeyFromBytes([]byte(sampleKey))
+
+	text, err := key.MarshalText()
+	c.Check(err, check.IsNil)
+	c.Check(string(text), check.Equals, sampleKey)
+
+	var k Key
+	c.Check(k.UnmarshalText([]byte(sampleKey)), check.IsNil)
+	c.Check(k.Fingerprint(), check.Equals, sampleHash)
+	c.Check(k.UnmarshalText([]byte("short")), check.ErrorMatches,
+		`Encryption keys must be.+`)
+
+	type config struct {
+		Key *Key `json:"key"`
+	}
+	out, err := json.Marshal(config{key})
+	c.Check(err, check.IsNil)
+	c.Check(string(out), check.Equals, `{"key":"d3161166-e89b-4158-af3b-5980e3056cc6\n"}`)
+
+	var cfg config
+	c.Check(json.Unmarshal(out, &cfg), check.IsNil)
+	c.Check(cfg.Key.Fingerprint(), check.Equals, sampleHash)
+	plain, err := cfg.Key.Decrypt("BFA25145OoPWwVZMdN/K7eDJUD5gLg916yildo6m+XG0+Sld7r+SuKXS3Rsi/TC0qbVZ5uCMBFA25145")
+	c.Check(err, check.IsNil)
+	c.Check(plain, check.Equals, "success")
+}
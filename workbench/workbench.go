@@ -32,6 +32,20 @@ type Key struct {
 	// The "hash" or checksum of the key is computed before we rotate the
 	// key, so we just store it once when reading.
 	hash string
+	// The source of IVs, or nil to use crypto/rand.
+	rand io.Reader
+}
+
+// GenerateKey returns the contents of a new key file: a random (version 4) UUID
+// literal, as Workbench itself generates.
+func GenerateKey() ([]byte, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Appendf(nil, "%x-%x-%x-%x-%x\n", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
 }
 
 // NewKeyFromBytes returns the key read from the given byte slice, or an error.
@@ -43,16 +57,14 @@ func NewKeyFromBytes(src []byte) (*Key, error) {
 	}
 
 	// For historical reasons, we always rotate incoming data.
-	xor := []byte{223, 99, 111, 160, 122, 212, 223, 105, 37, 190}
-	data := make([]byte, size)
-	copy(data, src)
-	for i := range data {
-		data[i] = data[i] ^ xor[i%len(xor)]
-	}
+	data := rotate(src)
 
 	// This is equivalent to rstudio-server's crc32HexHash().
 	checksum := fmt.Sprintf("%08X", crc32.ChecksumIEEE(src))
-	return &Key{data, checksum}, nil
+	if err := checkPolicy(src, checksum); err != nil {
+		return nil, err
+	}
+	return &Key{data: data, hash: checksum}, nil
 }
 
 // NewKeyFromReader returns the key read from an io.Reader, or an error.
@@ -65,6 +77,13 @@ func NewKeyFromReader(src io.Reader) (*Key, error) {
 }
 
 func (k *Key) Encrypt(s string) (string, error) {
+	p := crypt.CurrentPolicy()
+	if err := p.AllowsKey(k.hash); err != nil {
+		return "", err
+	}
+	if err := p.AllowsEncryption(crypt.AlgorithmAESCBC); err != nil {
+		return "", err
+	}
 	// This is AES-128-CBC.
 	out := make([]byte, len(s))
 	copy(out, []byte(s))
@@ -72,9 +91,16 @@ func (k *Key) Encrypt(s string) (string, error) {
 	// incorrect for this algorithm, but works with OpenSSL. We generate
 	// only 16 bytes but match the length for use as a prefix later.
 	iv := make([]byte, 32)
-	// As of Go 1.24, rand.Read() aborts rather than returning an error.
+	r := k.rand
+	if r == nil {
+		r = rand.Reader
+	}
+	// As of Go 1.24, rand.Read() aborts rather than returning an error, but
+	// other readers may not.
 	// See: https://go.dev/issue/66821
-	_, _ = rand.Read(iv[:16])
+	if _, err := io.ReadFull(r, iv[:16]); err != nil {
+		return "", err
+	}
 	// CBC requires that the input have a length divisible by the block size
 	// (which is 16) or be padded to that length using PKCS#7 padding. This
 	// padding uses the padding length itself as the padding byte, so e.g.
@@ -93,7 +119,21 @@ func (k *Key) Encrypt(s string) (string, error) {
 	return k.hash + encoded + k.hash, nil
 }
 
+// WithRand returns a copy of the key that reads IVs from r when encrypting.
+//
+// This is intended for tests that need stable cipher texts, and should never be
+// used with a predictable reader otherwise. The crypttest package provides a
+// suitable deterministic reader.
+func (k *Key) WithRand(r io.Reader) *Key {
+	out := *k
+	out.rand = r
+	return &out
+}
+
 func (k *Key) Decrypt(s string) (string, error) {
+	if err := crypt.CurrentPolicy().AllowsKey(k.hash); err != nil {
+		return "", err
+	}
 	if len(s) < minPayloadLength {
 		return "", crypt.ErrPayLoadTooShort
 	}
@@ -112,8 +152,8 @@ func (k *Key) Decrypt(s string) (string, error) {
 		return "", fmt.Errorf("failed to decode secret: %v", err)
 	}
 	// Check that the payload seems to have survived with its padding
-	// intact.
-	if len(buf)%aes.BlockSize != 0 {
+	// intact. It must hold the 32-byte IV and at least one block.
+	if len(buf)%aes.BlockSize != 0 || len(buf) < 32+aes.BlockSize {
 		return "", crypt.ErrPayLoadTooShort
 	}
 	// The actual encrypted payload is AES-128-CBC with the IV as a prefix.
@@ -131,10 +171,17 @@ func (k *Key) Decrypt(s string) (string, error) {
 	// Due to poor choices and the need to retain backwards compatibility,
 	// this standard library function has no way to signal an error.
 	mode.CryptBlocks(out, out)
-	// Now we need to truncate the PKCS#7 padding bytes. Padding can never
-	// be larger that aes.Blocksize, so we're safe to skip the length check
-	// here.
+	// Now we need to truncate the PKCS#7 padding bytes. Corrupt payloads can
+	// still carry a valid checksum, so the padding has to be checked.
 	pad := int(out[len(out)-1])
+	if pad < 1 || pad > aes.BlockSize || pad > len(out) {
+		return "", crypt.ErrFailedToDecrypt
+	}
+	for _, b := range out[len(out)-pad:] {
+		if int(b) != pad {
+			return "", crypt.ErrFailedToDecrypt
+		}
+	}
 	return string(out[:len(out)-pad]), nil
 }
 
@@ -146,3 +193,26 @@ func (k *Key) Decrypt(s string) (string, error) {
 func (k *Key) Fingerprint() string {
 	return k.hash
 }
+
+// Mode implements crypt.Cipher.
+func (k *Key) Mode() string {
+	return "workbench"
+}
+
+func init() {
+	crypt.RegisterMode("workbench", "Posit Workbench secure-cookie-key files",
+		func(src io.Reader) (crypt.Cipher, error) {
+			return NewKeyFromReader(src)
+		})
+}
+
+// rotate applies (or undoes) the same XOR rotation as crypt.Key.
+func rotate(src []byte) []byte {
+	xor := []byte{223, 99, 111, 160, 122, 212, 223, 105, 37, 190}
+	data := make([]byte, len(src))
+	copy(data, src)
+	for i := range data {
+		data[i] = data[i] ^ xor[i%len(xor)]
+	}
+	return data
+}
@@ -4,12 +4,18 @@
 package workbench
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"testing"
 
 	"gopkg.in/check.v1"
+
+	"github.com/rstudio/rskey/crypt"
 )
 
 const (
@@ -54,6 +60,14 @@ func (s *WorkbenchSuite) TestNewKey(c *check.C) {
 	c.Check(err, check.ErrorMatches, `cannot read`)
 }
 
+// encryptRaw encrypts data, which must already be padded, with a zero IV.
+func encryptRaw(k *Key, data []byte) string {
+	buf := make([]byte, 32+len(data))
+	block, _ := aes.NewCipher(k.data[:16])
+	cipher.NewCBCEncrypter(block, buf[:16]).CryptBlocks(buf[32:], data)
+	return k.hash + base64.StdEncoding.EncodeToString(buf) + k.hash
+}
+
 func (s *WorkbenchSuite) TestEncryption(c *check.C) {
 	k, _ := NewKeyFromBytes([]byte(sampleKey))
 
@@ -82,6 +96,19 @@ func (s *WorkbenchSuite) TestEncryption(c *check.C) {
 	_, err = k.Decrypt("BFA25145D75Xreg+vkkVgaFW3GOQvwzKHXUI5pOX4+2yJ5ZNJqZz7h4WEOxbeovH3GINg1E=BFA25145")
 	c.Check(err, check.ErrorMatches, `Payload is too short to be encrypted`)
 
+	// Valid checksums but invalid padding, as in corrupt values.
+	for _, last := range [][]byte{
+		{0}, {17}, {200}, {3, 3, 2}, bytes.Repeat([]byte{16}, 15),
+	} {
+		block := make([]byte, aes.BlockSize)
+		copy(block[len(block)-len(last):], last)
+		_, err = k.Decrypt(encryptRaw(k, block))
+		c.Check(err, check.Equals, crypt.ErrFailedToDecrypt, check.Commentf("%v", last))
+	}
+	text, err = k.Decrypt(encryptRaw(k, bytes.Repeat([]byte{16}, 16)))
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "")
+
 	// Roundtrip encryption test.
 	c1, err := k.Encrypt("some secret")
 	c.Check(err, check.IsNil)
@@ -106,6 +133,20 @@ func (s *WorkbenchSuite) TestEncryption(c *check.C) {
 	c.Check(len(c2), check.Not(check.Equals), len(c1))
 }
 
+func (s *WorkbenchSuite) TestGenerateKey(c *check.C) {
+	data, err := GenerateKey()
+	c.Assert(err, check.IsNil)
+	c.Check(string(data), check.Matches, `[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\n`)
+	a := Analyze(data)
+	c.Check(a.UUIDVersion, check.Equals, 4)
+	c.Check(a.Weak(), check.Equals, false)
+	_, err = NewKeyFromBytes(data)
+	c.Check(err, check.IsNil)
+	other, err := GenerateKey()
+	c.Assert(err, check.IsNil)
+	c.Check(other, check.Not(check.DeepEquals), data)
+}
+
 func (s *WorkbenchSuite) TestFingerprint(c *check.C) {
 	key, err := NewKeyFromBytes([]byte(sampleKey))
 	c.Check(err, check.IsNil)
@@ -116,3 +157,58 @@ func Test(t *testing.T) {
 	_ = check.Suite(&WorkbenchSuite{})
 	check.TestingT(t)
 }
+
+func (s *WorkbenchSuite) TestMode(c *check.C) {
+	cipher, err := crypt.LoadCipher("workbench", strings.NewReader(sampleKey))
+	c.Assert(err, check.IsNil)
+	c.Check(cipher.Mode(), check.Equals, "workbench")
+	c.Check(cipher.Fingerprint(), check.Equals, sampleHash)
+
+	text, err := cipher.Decrypt("BFA25145OoPWwVZMdN/K7eDJUD5gLg916yildo6m+XG0+Sld7r+SuKXS3Rsi/TC0qbVZ5uCMBFA25145")
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "success")
+}
+
+func (s *WorkbenchSuite) TestWithRand(c *check.C) {
+	k, _ := NewKeyFromBytes([]byte(sampleKey))
+
+	// The same IVs yield the same cipher texts.
+	iv := make([]byte, 16)
+	c1, err := k.WithRand(bytes.NewReader(iv)).Encrypt("success")
+	c.Check(err, check.IsNil)
+	c.Check(c1, check.Equals, "BFA25145AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAzOhrw0tIfbAuaPUx81GNXBFA25145")
+	text, err := k.Decrypt(c1)
+	c.Check(err, check.IsNil)
+	c.Check(text, check.Equals, "success")
+
+	// The original key is unchanged.
+	c2, err := k.Encrypt("success")
+	c.Check(err, check.IsNil)
+	c.Check(c2, check.Not(check.Equals), c1)
+
+	_, err = k.WithRand(&errReader{}).Encrypt("success")
+	c.Check(err, check.ErrorMatches, `cannot read`)
+}
+
+func (s *WorkbenchSuite) TestEncryptStable(c *check.C) {
+	key, err := NewKeyFromBytes([]byte("5a40aeab-ecba-4632-9499-0fc7fc300b78\n"))
+	c.Assert(err, check.IsNil)
+	other, err := NewKeyFromBytes([]byte("8f5b0cf2-4b2b-4a1e-9a4b-1a3b8c6d2e7f\n"))
+	c.Assert(err, check.IsNil)
+	previous, err := key.Encrypt("hunter2")
+	c.Assert(err, check.IsNil)
+	elsewhere, err := other.Encrypt("hunter2")
+	c.Assert(err, check.IsNil)
+
+	out, err := crypt.EncryptStable(key, "hunter2", previous)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Equals, previous)
+	for _, p := range []string{"", elsewhere} {
+		out, err = crypt.EncryptStable(key, "hunter2", p)
+		c.Check(err, check.IsNil)
+		c.Check(out, check.Not(check.Equals), p)
+	}
+	out, err = crypt.EncryptStable(key, "hunter3", previous)
+	c.Check(err, check.IsNil)
+	c.Check(out, check.Not(check.Equals), previous)
+}